@@ -47,19 +47,49 @@ func (w *WasmClient) Options() []map[string]string {
 // Change updates the compiler mode for WasmClient.
 // Implements HandlerSelection.Change: called with the selected option's value
 // ("L"/"M"/"S") when the user confirms a mode (radio) or presses a global shortcut.
+// Switching to a TinyGo mode first runs the compatibility pre-flight; see
+// SetOnTinyGoPreflight for how unsupported packages are confirmed.
 func (w *WasmClient) Change(newValue string) {
-	// Normalize input: trim spaces and convert to uppercase
-	newValue = Convert(newValue).ToUpper().String()
+	w.change(newValue, w.defaultPreflightDecision)
+}
 
-	// Validate mode
-	if err := w.ValidateMode(newValue); err != nil {
+// change implements Change with the given pre-flight decision and returns the
+// mode actually applied ("" when invalid, aborted or TinyGo failed to
+// install), the pre-flight report (nil when no analysis ran) and the error of
+// an invalid mode, the install or the compilation (the mode is applied then).
+func (w *WasmClient) change(newValue string, decide func(string, *TinyGoReport) PreflightDecision) (string, *TinyGoReport, error) {
+	// Resolve the shortcut (uppercase) or custom profile of tinywasm.json
+	newValue, profile, err := w.resolveMode(newValue)
+	if err != nil {
 		w.Logger(err.Error())
-		return
+		return "", nil, err
 	}
 
-	w.storageMu.RLock()
+	current := w.Value()
+	if w.RequiresTinyGo(newValue) && !w.RequiresTinyGo(current) {
+		w.reportProgress(ProgressPreflight, newValue, "checking the imports for packages TinyGo cannot compile")
+	}
+
+	// Analyze the import graph before installing TinyGo or compiling, so an
+	// incompatible project shows a short findings list instead of compiler errors.
+	mode := newValue
+	newValue, report := w.preflightTinyGo(current, newValue, decide)
+	if newValue == "" {
+		return "", report, nil
+	}
+	if newValue != mode {
+		profile = "" // PreflightLarge leaves the profile's TinyGo mode
+	}
+	if report != nil && newValue == current && profile == w.activeProfile() {
+		// PreflightLarge while already in the stdlib mode: nothing to recompile
+		w.Logger("Staying in mode " + current)
+		return current, report, nil
+	}
+
+	w.storageMu.Lock()
+	w.preflightPending = ""
 	modeChanged := newValue != w.CurrentSizeMode
-	w.storageMu.RUnlock()
+	w.storageMu.Unlock()
 
 	// Everything from here on can take a moment (TinyGo install check,
 	// recompiling the wasm binary) — LogOpen/LogClose drive the TUI's
@@ -71,26 +101,34 @@ func (w *WasmClient) Change(newValue string) {
 	if w.RequiresTinyGo(newValue) {
 		w.verifyTinyGoInstallationStatus()
 		if !w.TinyGoInstalled {
+			w.reportProgress(ProgressInstall, newValue, "installing TinyGo")
 			if err := w.handleTinyGoMissing(); err != nil {
 				w.Logger(tui.LogClose, err.Error())
-				return
+				w.reportProgress(ProgressFailed, newValue, err.Error())
+				return "", report, err
 			}
 			// TinyGo installed successfully — update status so builders use it
 			w.TinyGoInstalled = true
 		}
 	}
 
-	// Update active builder
+	// Switch the profile's arguments and env, then the active builder
+	w.useProfile(profile)
 	w.UpdateCurrentBuilder(newValue)
 
-	// Save mode to store if available
+	// Save mode (or profile) to store if available
 	if w.Database != nil {
-		w.Database.Set(StoreKeySizeMode, newValue)
+		stored := newValue
+		if profile != "" {
+			stored = profile
+		}
+		w.Database.Set(StoreKeySizeMode, stored)
 	}
 
 	// Auto-recompile
 	compilationSuccess := true
-	if err := w.RecompileMainWasm(); err != nil {
+	compileErr := w.RecompileMainWasm()
+	if err := compileErr; err != nil {
 		errorMsg := lang.Translate("Error:", "auto", "compilation", "failed:", err).String()
 		//errorMsg = "Error: auto compilation failed: " + err.Error()
 		w.Logger(tui.LogClose, errorMsg)
@@ -101,6 +139,8 @@ func (w *WasmClient) Change(newValue string) {
 	// Only notify listener when compilation succeeded and mode actually changed.
 	// If compilation failed, the new mode's runtime would mismatch with the
 	// old mode's .wasm binary, causing the browser to freeze on reload.
+	// The storage already regenerated the page bootstrap (if enabled) on the
+	// successful compile, so it matches the new runtime by now.
 	if compilationSuccess && modeChanged && w.OnWasmExecChange != nil {
 		w.OnWasmExecChange()
 	}
@@ -109,6 +149,7 @@ func (w *WasmClient) Change(newValue string) {
 		event, suffix := w.buildSuccessMessage("Changed", "To", "Mode", newValue)
 		w.Logger(tui.LogClose, event, " ", suffix)
 	}
+	return newValue, report, compileErr
 }
 
 // RecompileMainWasm recompiles the main WASM file using the current Storage mode.
@@ -122,7 +163,10 @@ func (w *WasmClient) RecompileMainWasm() error {
 	}
 
 	// Use Storage.Compile() to respect In-Memory vs Disk mode
-	err := s.Compile()
+	mode := w.Value()
+	w.reportProgress(ProgressCompile, mode, "compiling mode "+mode)
+	err := w.compileStorage(s)
+	w.reportCompiled(mode, err)
 
 	if w.OnCompile != nil {
 		w.OnCompile(err)
@@ -131,25 +175,11 @@ func (w *WasmClient) RecompileMainWasm() error {
 	return err
 }
 
-// ValidateMode validates if the provided mode is supported
+// ValidateMode validates if the provided mode is supported: a mode shortcut
+// or a custom profile of tinywasm.json.
 func (w *WasmClient) ValidateMode(mode string) error {
-	// Ensure mode is uppercase to match configured shortcuts which are
-	// expected to be single uppercase letters by default.
-	mode = Convert(mode).ToUpper().String()
-
-	validModes := []string{
-		Convert(w.buildLargeSizeShortcut).ToUpper().String(),
-		Convert(w.buildMediumSizeShortcut).ToUpper().String(),
-		Convert(w.buildSmallSizeShortcut).ToUpper().String(),
-	}
-
-	for _, valid := range validModes {
-		if mode == valid {
-			return nil
-		}
-	}
-
-	return Err("mode", ":", mode, "invalid", "valid", ":", validModes)
+	_, _, err := w.resolveMode(mode)
+	return err
 }
 
 func (w *WasmClient) storageMode() string {
@@ -167,11 +197,7 @@ func (w *WasmClient) storageMode() string {
 // log line rather than emitting it as a separate call.
 func (w *WasmClient) buildSuccessMessage(messages ...any) (event, suffix string) {
 	event = lang.Translate(messages...).String()
-	binarySize := "unknown"
-	if sizer, ok := w.activeSizeBuilder.(interface{ BinarySize() string }); ok {
-		binarySize = sizer.BinarySize()
-	}
-	suffix = Sprintf("[%s|%s]", w.storageMode(), binarySize)
+	suffix = Sprintf("[%s|%s]", w.storageMode(), w.binarySize())
 	return event, suffix
 }
 
@@ -4,5 +4,5 @@ package main
 
 // Stub for native toolchains (gopls, go build ./..., vet): this package is a
 // wasm-only benchmark fixture — the real main lives in main.go (js && wasm)
-// and is compiled by benchmark/scripts with GOOS=js GOARCH=wasm.
+// and is compiled by `wasmbuild -matrix` (see benchmark/README.md).
 func main() {}
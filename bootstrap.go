@@ -0,0 +1,102 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tinywasm/js"
+)
+
+// jsDefaultWasmURL is the wasm URL hardcoded by js.PageBootstrap().
+const jsDefaultWasmURL = "/client.wasm"
+
+// bootstrapState caches the page bootstrap generated for the last compiled runtime.
+type bootstrapState struct {
+	mu      sync.RWMutex
+	content []byte
+	runtime js.Runtime
+	wasmURL string
+}
+
+// jsRuntimeMu serializes pageBootstrap: js.SetRuntime is global, and clients
+// building different modes in parallel (eg: RunWasmMatrix) must not mix runtimes.
+var jsRuntimeMu sync.Mutex
+
+// pageBootstrap returns the page bootstrap script: the wasm_exec.js of rt plus
+// the loader that fetches and runs the binary served at wasmURL. The global js
+// runtime is restored afterwards for other users of tinywasm/js; it has no
+// getter, so the previous runtime is told from the bootstrap it generated.
+func pageBootstrap(rt js.Runtime, wasmURL string) []byte {
+	jsRuntimeMu.Lock()
+	before := js.PageBootstrap().Content
+	js.SetRuntime(rt)
+	content := js.PageBootstrap().Content
+	if content != before {
+		// js.Runtime has two values: the previous one is the other
+		previous := js.RuntimeGo
+		if rt == js.RuntimeGo {
+			previous = js.RuntimeTinyGo
+		}
+		js.SetRuntime(previous)
+	}
+	jsRuntimeMu.Unlock()
+	if wasmURL != jsDefaultWasmURL {
+		content = strings.ReplaceAll(content, `"`+jsDefaultWasmURL+`"`, `"`+wasmURL+`"`)
+	}
+	return []byte(content)
+}
+
+// BootstrapScript returns the page bootstrap matching the last compiled binary.
+// Returns nil when SetBootstrapName was not called or nothing was compiled yet.
+func (w *WasmClient) BootstrapScript() []byte {
+	w.bootstrap.mu.RLock()
+	defer w.bootstrap.mu.RUnlock()
+	return w.bootstrap.content
+}
+
+// bootstrapRoutePath returns the URL path of the page bootstrap script
+func (w *WasmClient) bootstrapRoutePath() string {
+	return w.assetRoutePath(w.BootstrapName)
+}
+
+// syncBootstrap regenerates the page bootstrap after a successful compilation
+// when the runtime (or wasm route) differs from the one it was generated for,
+// so the served script always matches the served binary.
+func (w *WasmClient) syncBootstrap() (content []byte, changed bool) {
+	if w.BootstrapName == "" {
+		return nil, false
+	}
+
+	rt := w.runtimeForMode(w.Value())
+	wasmURL := w.wasmRoutePath()
+
+	w.bootstrap.mu.Lock()
+	defer w.bootstrap.mu.Unlock()
+
+	if w.bootstrap.content == nil || w.bootstrap.runtime != rt || w.bootstrap.wasmURL != wasmURL {
+		w.bootstrap.content = pageBootstrap(rt, wasmURL)
+		w.bootstrap.runtime = rt
+		w.bootstrap.wasmURL = wasmURL
+		changed = true
+	}
+	return w.bootstrap.content, changed
+}
+
+// writeBootstrap writes the page bootstrap next to the wasm file when it was
+// regenerated or is missing on disk.
+func (w *WasmClient) writeBootstrap() error {
+	content, changed := w.syncBootstrap()
+	if content == nil {
+		return nil
+	}
+
+	path := filepath.Join(w.AppRootDir, w.Config.OutputDir(), w.BootstrapName)
+	if !changed {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+	return os.WriteFile(path, content, 0644)
+}
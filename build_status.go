@@ -0,0 +1,271 @@
+package client
+
+import (
+	"os"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/gobuild"
+)
+
+// buildRecord is the metadata of the last compilation, kept next to
+// lastBuildError for BuildStatus.
+type buildRecord struct {
+	at       time.Time // when the build finished, zero before the first one
+	duration time.Duration
+	size     int64 // bytes of the binary, 0 when failed or unknown
+}
+
+// recordBuild stores the outcome of a compilation started at start.
+func (w *WasmClient) recordBuild(start time.Time, err error) {
+	var size int64
+	if err == nil {
+		size = w.builtSize()
+	}
+	w.storageMu.Lock()
+	defer w.storageMu.Unlock()
+	w.lastBuildError = err
+	w.lastBuild = buildRecord{at: time.Now(), duration: time.Since(start), size: size}
+	if size > 0 {
+		w.setModeSize(w.compiledMode(), size)
+	}
+}
+
+// compileStorage runs s.Compile and records the outcome. A DiskStorage build
+// running in the background (Config.Callback) records it once it ends.
+func (w *WasmClient) compileStorage(s BuildStorage) error {
+	start := time.Now()
+	err := s.Compile()
+	if d, ok := s.(*DiskStorage); !ok || !d.background() {
+		w.recordBuild(start, err)
+	}
+	return err
+}
+
+// setModeSize records size as the last known binary size of mode. The caller
+// holds storageMu.
+func (w *WasmClient) setModeSize(mode string, size int64) {
+	if w.modeSizes == nil {
+		w.modeSizes = map[string]int64{}
+	}
+	w.modeSizes[mode] = size
+}
+
+// ModeSize returns the last known binary size of mode in bytes, 0 when it
+// has not been built successfully yet.
+func (w *WasmClient) ModeSize(mode string) int64 {
+	w.storageMu.RLock()
+	defer w.storageMu.RUnlock()
+	return w.modeSizes[mode]
+}
+
+// builtSize returns the size of the binary held by the current Storage.
+func (w *WasmClient) builtSize() int64 {
+	w.storageMu.RLock()
+	store, builder := w.Storage, w.activeSizeBuilder
+	w.storageMu.RUnlock()
+
+	if mem, ok := store.(*MemoryStorage); ok {
+		mem.Mu.RLock()
+		defer mem.Mu.RUnlock()
+		return int64(len(mem.WasmContent))
+	}
+	path := ""
+	if disk, ok := store.(*DiskStorage); ok {
+		path = disk.path()
+	} else if builder != nil {
+		path = builder.FinalOutputPath()
+	}
+	if path == "" {
+		return 0
+	}
+	if info, err := os.Stat(path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// BuildStatus returns the current mode and storage with the outcome of the
+// last compilation: State is "ok", "failed" or "none" when nothing was
+// compiled yet, and a failed build carries its compiler diagnostics.
+func (w *WasmClient) BuildStatus() *BuildStatus {
+	mode := w.Value()
+	status := &BuildStatus{Mode: mode, Compiler: "go", State: "none"}
+	if w.RequiresTinyGo(mode) {
+		status.Compiler = "tinygo"
+	}
+
+	w.storageMu.RLock()
+	defer w.storageMu.RUnlock()
+	if w.Storage != nil {
+		status.Storage = w.Storage.Name()
+	}
+	if w.lastBuild.at.IsZero() && w.lastBuildError == nil {
+		return status
+	}
+	status.setOutcome(w.lastBuild, w.lastBuildError)
+	return status
+}
+
+// setOutcome fills the build fields of s from rec and err.
+func (s *BuildStatus) setOutcome(rec buildRecord, err error) {
+	if !rec.at.IsZero() {
+		s.BuiltAt = rec.at.UTC().Format(time.RFC3339)
+		s.DurationMs = rec.duration.Milliseconds()
+	}
+	if err == nil {
+		s.State, s.Size = "ok", rec.size
+		return
+	}
+	s.State, s.Error = "failed", err.Error()
+	for _, d := range compileDiagnostics(err) {
+		s.Diagnostics = append(s.Diagnostics, &BuildDiagnostic{
+			File: d.File, Line: int64(d.Line), Column: int64(d.Column), Message: d.Message,
+		})
+	}
+}
+
+// DefaultCompileTimeout bounds CompileAndWait when no timeout is given.
+const DefaultCompileTimeout = 2 * time.Minute
+
+// CompileAndWait compiles and returns the outcome once the build finished.
+// With mode empty or equal to the current one it runs RecompileMainWasm, so
+// the served binary, BuildStatus and OnCompile follow as on a file event; a
+// disk build running in the background (Config.Callback) is waited for.
+// Another mode is compiled in memory with that mode's builder only to check
+// it: neither the current mode, the Database nor the served binary change.
+// Compile failures are reported in the status; the error is for an invalid
+// mode, a missing TinyGo or a build still running after timeout (cancelled).
+func (w *WasmClient) CompileAndWait(mode string, timeout time.Duration) (*BuildStatus, error) {
+	if timeout <= 0 {
+		timeout = DefaultCompileTimeout
+	}
+	current := w.Value()
+	mode, profile, err := w.modeOverride(mode)
+	if err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		w.storageMu.RLock()
+		builder, store := w.activeSizeBuilder, w.Storage
+		w.storageMu.RUnlock()
+		compile := func() error {
+			err := w.RecompileMainWasm()
+			if d, ok := store.(*DiskStorage); ok {
+				d.wait() // a Config.Callback build returns before it ends
+			}
+			return err
+		}
+		if !waitCompile(builder, timeout, compile) {
+			return nil, compileTimeoutError(current, timeout)
+		}
+		return w.BuildStatus(), nil
+	}
+
+	status := &BuildStatus{Mode: mode, Compiler: "go", Storage: "none (mode override, not served)"}
+	if w.RequiresTinyGo(mode) {
+		status.Compiler = "tinygo"
+	}
+	start := time.Now()
+	content, compileErr, err := w.compileModeToMemory(mode, profile, timeout)
+	if err != nil {
+		return nil, err
+	}
+	rec := buildRecord{at: time.Now(), duration: time.Since(start)}
+	if compileErr == nil {
+		rec.size = int64(len(content))
+	}
+	status.setOutcome(rec, compileErr)
+	return status, nil
+}
+
+// modeOverride resolves the mode argument of CompileAndWait and SizeReport,
+// a shortcut or a custom profile of tinywasm.json, to its shortcut and
+// profile; mode is "" when name is empty or names what is in use.
+func (w *WasmClient) modeOverride(name string) (mode, profile string, err error) {
+	if name == "" {
+		return "", "", nil
+	}
+	if mode, profile, err = w.resolveMode(name); err != nil {
+		return "", "", err
+	}
+	if mode == w.Value() && profile == w.activeProfile() {
+		return "", "", nil
+	}
+	return mode, profile, nil
+}
+
+// compileModeToMemory compiles mode into memory with its builder, or with
+// one of its own when profile is not the custom profile in use, leaving the
+// current mode and the served binary alone. compileErr is the compiler's
+// failure; err an invalid mode, a missing TinyGo or the timeout.
+func (w *WasmClient) compileModeToMemory(mode, profile string, timeout time.Duration) (content []byte, compileErr, err error) {
+	if err := w.ValidateMode(mode); err != nil {
+		return nil, nil, err
+	}
+	if w.RequiresTinyGo(mode) {
+		w.verifyTinyGoInstallationStatus()
+		if !w.TinyGoInstalled {
+			return nil, nil, Errf("TinyGo is not installed: select mode %s with wasm_set_mode to install it", mode)
+		}
+	}
+	w.storageMu.RLock()
+	builder, own := w.builderForMode(mode), profile == w.profile
+	w.storageMu.RUnlock()
+	if !own {
+		large, medium, small := w.newBuilders(w.profileCompiler(profile))
+		builder = map[string]gobuild.Compiler{
+			w.buildLargeSizeShortcut:  large,
+			w.buildMediumSizeShortcut: medium,
+			w.buildSmallSizeShortcut:  small,
+		}[mode]
+	}
+	c, ok := builder.(interface {
+		CompileToMemory() ([]byte, error)
+	})
+	if !ok {
+		return nil, nil, Errf("the builder of mode %s does not support CompileToMemory", mode)
+	}
+	var out []byte
+	var outErr error
+	w.reportProgress(ProgressCompile, mode, "compiling mode "+mode+" in memory")
+	if !waitCompile(builder, timeout, func() error {
+		out, outErr = c.CompileToMemory()
+		return outErr
+	}) {
+		err := compileTimeoutError(mode, timeout)
+		w.reportProgress(ProgressFailed, mode, err.Error())
+		return nil, nil, err
+	}
+	w.reportCompiled(mode, outErr)
+	if outErr == nil && len(out) > 0 && own {
+		w.storageMu.Lock()
+		w.setModeSize(mode, int64(len(out)))
+		w.storageMu.Unlock()
+	}
+	return out, outErr, nil
+}
+
+// waitCompile runs compile and waits for it up to timeout. When it expires
+// the builder's compilation is cancelled and false is returned; compile keeps
+// running in the background until the compiler exits.
+func waitCompile(builder gobuild.Compiler, timeout time.Duration, compile func() error) bool {
+	done := make(chan struct{})
+	go func() {
+		compile()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		if c, ok := builder.(interface{ Cancel() error }); ok {
+			c.Cancel()
+		}
+		return false
+	}
+}
+
+func compileTimeoutError(mode string, timeout time.Duration) error {
+	return Errf("mode %s compilation did not finish within %s and was cancelled", mode, timeout.String())
+}
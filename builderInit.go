@@ -10,6 +10,28 @@ import (
 
 // builderWasmInit configures 3 builders for WASM compilation modes
 func (w *WasmClient) builderWasmInit() {
+	w.builderSizeLarge, w.builderSizeMedium, w.builderSizeSmall = w.newBuilders(func() []string {
+		if w.CompilingArguments != nil {
+			return w.CompilingArguments()
+		}
+		return nil
+	}, w.Config.Env)
+
+	// Sync active builder with current mode (don't always reset to Large)
+	// This is important when builderWasmInit is called after loadMode() (e.g., from SetAppRootDir)
+	switch w.CurrentSizeMode {
+	case w.buildMediumSizeShortcut: // "M"
+		w.activeSizeBuilder = w.builderSizeMedium
+	case w.buildSmallSizeShortcut: // "S"
+		w.activeSizeBuilder = w.builderSizeSmall
+	default: // "L" or empty
+		w.activeSizeBuilder = w.builderSizeLarge
+	}
+}
+
+// newBuilders returns the Large, Medium and Small builders compiling with the
+// extra arguments of extraArgs and the environment env.
+func (w *WasmClient) newBuilders(extraArgs func() []string, env []string) (large, medium, small gobuild.Compiler) {
 	sourceDir := filepath.Join(w.AppRootDir, w.Config.SourceDir())
 	outputDir := filepath.Join(w.AppRootDir, w.Config.OutputDir())
 	mainInputFileRelativePath := filepath.Join(sourceDir, w.MainInputFile)
@@ -25,7 +47,13 @@ func (w *WasmClient) builderWasmInit() {
 		OutFolderRelativePath:     outputDir,
 		Logger:                    nil,
 		Timeout:                   60 * time.Second, // 1 minute for all modes
-		Callback:                  w.Callback,
+		// No Callback: DiskStorage runs Config.Callback builds in the background
+		// itself so it can validate the binary before it replaces the served one.
+	}
+
+	// Custom env, plus the pinned toolchain of reproducible builds
+	if w.Reproducible {
+		env = append(append([]string{}, env...), w.reproducibleEnv(env)...)
 	}
 
 	// Configure Coding builder (Go standard)
@@ -33,61 +61,48 @@ func (w *WasmClient) builderWasmInit() {
 	codingConfig.Command = "go"
 	codingConfig.Env = []string{"GOOS=js", "GOARCH=wasm"}
 	// Append custom env from Config if provided
-	if len(w.Config.Env) > 0 {
-		codingConfig.Env = append(codingConfig.Env, w.Config.Env...)
+	if len(env) > 0 {
+		codingConfig.Env = append(codingConfig.Env, env...)
 	}
 	codingConfig.CompilingArguments = func() []string {
 		args := []string{"-tags", "dev"}
-		if w.CompilingArguments != nil {
-			args = append(args, w.CompilingArguments()...)
+		args = append(args, extraArgs()...)
+		if w.Reproducible {
+			args = reproducibleGoArgs(args)
 		}
 		args = append(args, "-p", "1")
 		return args
 	}
-	w.builderSizeLarge = gobuild.New(&codingConfig)
+	large = gobuild.New(&codingConfig)
 
 	// Configure Debug builder (TinyGo debug-friendly)
 	debugConfig := baseConfig
 	debugConfig.Command = "tinygo"
-	if len(w.Config.Env) > 0 {
-		debugConfig.Env = w.Config.Env
+	if len(env) > 0 {
+		debugConfig.Env = env
 	}
 	debugConfig.CompilingArguments = func() []string {
 		args := []string{"-target", "wasm", "-opt=1"} // Keep debug symbols
-		if w.CompilingArguments != nil {
-			args = append(args, w.CompilingArguments()...)
-		}
+		args = append(args, extraArgs()...)
 		args = append(args, "-p", "1") // Add -p 1
 		return args
 	}
-	w.builderSizeMedium = gobuild.New(&debugConfig)
+	medium = gobuild.New(&debugConfig)
 
 	// Configure Production builder (TinyGo optimized)
 	prodConfig := baseConfig
 	prodConfig.Command = "tinygo"
-	if len(w.Config.Env) > 0 {
-		prodConfig.Env = w.Config.Env
+	if len(env) > 0 {
+		prodConfig.Env = env
 	}
 	prodConfig.CompilingArguments = func() []string {
 		args := []string{"-target", "wasm", "-opt=z", "-no-debug", "-panic=trap"}
-		if w.CompilingArguments != nil {
-			args = append(args, w.CompilingArguments()...)
-		}
+		args = append(args, extraArgs()...)
 		args = append(args, "-p", "1")
 		return args
 	}
-	w.builderSizeSmall = gobuild.New(&prodConfig)
-
-	// Sync active builder with current mode (don't always reset to Large)
-	// This is important when builderWasmInit is called after loadMode() (e.g., from SetAppRootDir)
-	switch w.CurrentSizeMode {
-	case w.buildMediumSizeShortcut: // "M"
-		w.activeSizeBuilder = w.builderSizeMedium
-	case w.buildSmallSizeShortcut: // "S"
-		w.activeSizeBuilder = w.builderSizeSmall
-	default: // "L" or empty
-		w.activeSizeBuilder = w.builderSizeLarge
-	}
+	small = gobuild.New(&prodConfig)
+	return large, medium, small
 }
 
 // UpdateCurrentBuilder sets the activeSizeBuilder based on mode and cancels ongoing operations
@@ -104,15 +119,20 @@ func (w *WasmClient) UpdateCurrentBuilder(mode string) {
 	w.TinyGoCompilerFlag = w.RequiresTinyGo(mode)
 
 	// 3. Set activeSizeBuilder based on mode
+	w.activeSizeBuilder = w.builderForMode(mode)
+}
+
+// builderForMode returns the builder of mode, the Large one for unknown modes.
+func (w *WasmClient) builderForMode(mode string) gobuild.Compiler {
 	switch mode {
 	case w.buildLargeSizeShortcut: // "L"
-		w.activeSizeBuilder = w.builderSizeLarge
+		return w.builderSizeLarge
 	case w.buildMediumSizeShortcut: // "M"
-		w.activeSizeBuilder = w.builderSizeMedium
+		return w.builderSizeMedium
 	case w.buildSmallSizeShortcut: // "S"
-		w.activeSizeBuilder = w.builderSizeSmall
+		return w.builderSizeSmall
 	default:
-		w.activeSizeBuilder = w.builderSizeLarge // fallback to coding mode
+		return w.builderSizeLarge // fallback to coding mode
 	}
 }
 
@@ -35,6 +35,7 @@ type WasmClient struct {
 	AppRootDir                string
 	MainInputFile             string
 	OutputName                string
+	BootstrapName             string // optional page bootstrap file, eg: "script.js" ("" = disabled)
 	buildLargeSizeShortcut    string
 	buildMediumSizeShortcut   string
 	buildSmallSizeShortcut    string
@@ -46,11 +47,46 @@ type WasmClient struct {
 	// err==nil indicates success; err!=nil indicates failure.
 	OnCompile func(err error)
 
+	// OnProgress receives the steps of mode changes and compilations (see progress.go).
+	OnProgress func(BuildProgress)
+
+	// OnTinyGoPreflight decides whether to switch to a TinyGo mode whose
+	// pre-flight found unsupported packages (see preflight.go).
+	OnTinyGoPreflight func(mode string, report *TinyGoReport) PreflightDecision
+
 	// lastBuildError stores the error from the most recent compilation attempt.
 	lastBuildError error
 
-	// storageMu protects Storage, CurrentSizeMode and lastBuildError fields from concurrent access
+	// lastBuild holds the time, duration and size of that attempt (see build_status.go).
+	lastBuild buildRecord
+
+	// modeSizes holds the last successful binary size of each mode, by shortcut.
+	modeSizes map[string]int64
+
+	// templateSources are the user template directories added with AddTemplates.
+	templateSources []templateSource
+
+	// projectConfigErr is the error of the last tinywasm.json load (see project_config.go).
+	projectConfigErr error
+
+	// project is the tinywasm.json of the last load (nil without one),
+	// profile its custom profile in use ("" for none) and codeArgs/codeEnv
+	// the compiler arguments and env set in code, kept for when neither the
+	// file nor the profile sets any.
+	project  *ProjectConfig
+	profile  string
+	codeArgs func() []string
+	codeEnv  []string
+
+	// preflightPending is the TinyGo mode whose pre-flight findings were shown
+	// and await confirmation by selecting it again.
+	preflightPending string
+
+	// storageMu protects Storage, CurrentSizeMode, profile, lastBuildError, lastBuild, modeSizes and preflightPending fields from concurrent access
 	storageMu sync.RWMutex
+
+	// bootstrap holds the generated page bootstrap script (see bootstrap.go)
+	bootstrap bootstrapState
 }
 
 // New creates a new WasmClient instance with the provided configuration
@@ -89,6 +125,9 @@ func New(c *Config) *WasmClient {
 		ShouldGenerateDefaultFile: func() bool { return false },
 	}
 
+	// Apply tinywasm.json from AppRootDir, if present
+	w.loadProjectConfig()
+
 	// Initialize gobuild instance with WASM-specific configuration
 	w.builderWasmInit()
 
@@ -103,7 +142,16 @@ func New(c *Config) *WasmClient {
 
 // wasmRoutePath calculates the URL path for the WASM file
 func (w *WasmClient) wasmRoutePath() string {
-	prefix := w.Config.AssetsURLPrefix
+	return w.assetRoutePath(w.OutputName + ".wasm")
+}
+
+// assetRoutePath calculates the URL path for a file served under AssetsURLPrefix
+func (w *WasmClient) assetRoutePath(fileName string) string {
+	return assetURLPath(w.Config.AssetsURLPrefix, fileName)
+}
+
+// assetURLPath joins an optional URL prefix and a file name into an absolute URL path
+func assetURLPath(prefix, fileName string) string {
 	// Ensure safe joining of URL paths
 	if prefix != "" {
 		// Clean the prefix
@@ -113,9 +161,9 @@ func (w *WasmClient) wasmRoutePath() string {
 		if prefix[len(prefix)-1] == '/' {
 			prefix = prefix[:len(prefix)-1]
 		}
-		return "/" + prefix + "/" + w.OutputName + ".wasm"
+		return "/" + prefix + "/" + fileName
 	}
-	return "/" + w.OutputName + ".wasm"
+	return "/" + fileName
 }
 
 // Name returns the name of the WASM project
@@ -180,6 +228,11 @@ func (w *WasmClient) UseMemoryStorage() {
 func (w *WasmClient) loadMode() {
 	if w.Database != nil {
 		if val, err := w.Database.Get(StoreKeySizeMode); err == nil && val != "" {
+			// a stored custom profile restores its arguments and env too
+			if mode, profile, err := w.resolveMode(val); err == nil {
+				w.useProfile(profile)
+				val = mode
+			}
 			w.storageMu.Lock()
 			defer w.storageMu.Unlock()
 			// Only update if the mode is different from current
@@ -194,9 +247,15 @@ func (w *WasmClient) loadMode() {
 }
 
 // SetAppRootDir sets the application root directory (absolute).
+// The project's tinywasm.json, if present, is applied again from the new root.
 func (w *WasmClient) SetAppRootDir(path string) {
 	w.AppRootDir = path
+	modeSet := w.loadProjectConfig()
 	w.builderWasmInit()
+	if modeSet {
+		// a mode stored by the user wins over the file's initial mode
+		w.loadMode()
+	}
 }
 
 // SetMainInputFile sets the main input file for WASM compilation (default: "client.go").
@@ -211,6 +270,14 @@ func (w *WasmClient) SetOutputName(name string) {
 	w.builderWasmInit()
 }
 
+// SetBootstrapName enables the page bootstrap script (wasm_exec.js + loader) with
+// the given file name, eg: "script.js". It is regenerated whenever the runtime
+// changes, served by RegisterRoutes and, in disk mode, also written next to the
+// wasm file. An empty name disables it (default).
+func (w *WasmClient) SetBootstrapName(name string) {
+	w.BootstrapName = name
+}
+
 // SetBuildShortcuts sets the shortcuts for the three compilation modes.
 // If an empty string is provided for a shortcut, it remains unchanged.
 func (w *WasmClient) SetBuildShortcuts(large, medium, small string) {
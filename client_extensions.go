@@ -12,6 +12,13 @@ func (w *WasmClient) SetActiveBuilder(c gobuild.Compiler) {
 	w.activeSizeBuilder = c
 }
 
+// activeBuilder returns the builder of the current mode.
+func (w *WasmClient) activeBuilder() gobuild.Compiler {
+	w.storageMu.RLock()
+	defer w.storageMu.RUnlock()
+	return w.activeSizeBuilder
+}
+
 // SetBuilders allows injecting mock builders for all modes.
 func (w *WasmClient) SetBuilders(large, medium, small gobuild.Compiler) {
 	w.storageMu.Lock()
@@ -67,12 +74,13 @@ func (w *WasmClient) UseStandardGo() {
 // This exposes the underlying Storage's Compile method.
 func (w *WasmClient) Compile() error {
 	w.storageMu.RLock()
-	defer w.storageMu.RUnlock()
+	s := w.Storage
+	w.storageMu.RUnlock()
 
-	if w.Storage == nil {
+	if s == nil {
 		return nil
 	}
-	return w.Storage.Compile()
+	return w.compileStorage(s)
 }
 
 // SetOnCompile. registers a callback invoked after each compilation
@@ -0,0 +1,352 @@
+package client
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/markdown"
+)
+
+// ClientTemplate is a named entry point template: a markdown document whose
+// fenced go, js (or javascript) and css code blocks become its files, by
+// language or by a file=path attribute on the fence (see render).
+type ClientTemplate struct {
+	Name        string   `json:"name"`              // eg: "counter"
+	Description string   `json:"description"`       // one line shown by the CLI and the MCP tool
+	File        string   `json:"file"`              // markdown file holding the code, inside Source
+	Modules     []string `json:"modules,omitempty"` // modules the generated code imports, added to go.mod
+	// Files are the files written, relative to SourceDir, one per language
+	// (.go, .js, .css). The .go one is the entry point and is written as the
+	// main input file whatever its name here. Empty means the entry point only.
+	Files  []string `json:"files,omitempty"`
+	Source string   `json:"source"` // BuiltinTemplateSource or the directory the template was loaded from
+
+	fsys fs.FS // holds File
+}
+
+// BuiltinTemplateSource is the Source of the templates embedded in the module.
+const BuiltinTemplateSource = "built-in"
+
+// DefaultClientTemplate is the template Scaffold uses when none is given.
+const DefaultClientTemplate = "counter"
+
+// clientTemplates is the registry of built-in templates. Keep each Modules
+// list in sync with the imports of its markdown file.
+var clientTemplates = []ClientTemplate{
+	{
+		Name:        "counter",
+		Description: "click counter component with a signal-bound label (tinywasm/dom + html)",
+		File:        "templates/basic_wasm_client.md",
+		Modules:     []string{"github.com/tinywasm/dom", "github.com/tinywasm/fmt", "github.com/tinywasm/html"},
+	},
+	{
+		Name:        "router",
+		Description: "single page application with a route table and navigation",
+		File:        "templates/router_spa_client.md",
+		Modules:     []string{"github.com/tinywasm/dom", "github.com/tinywasm/html"},
+	},
+	{
+		Name:        "crud",
+		Description: "form that creates, lists, edits and deletes records in memory",
+		File:        "templates/form_crud_client.md",
+		Modules:     []string{"github.com/tinywasm/dom", "github.com/tinywasm/fmt", "github.com/tinywasm/html"},
+	},
+	{
+		Name:        "worker",
+		Description: "Web Worker answering page messages (tinywasm/js, no DOM)",
+		File:        "templates/web_worker_client.md",
+		Modules:     []string{"github.com/tinywasm/context", "github.com/tinywasm/js"},
+	},
+	{
+		Name:        "minimal",
+		Description: "main without imports: no syscall/js, no DOM, smallest binary",
+		File:        "templates/minimal_client.md",
+	},
+}
+
+// ClientTemplates returns the built-in templates. WasmClient.Templates adds
+// the user templates to them.
+func ClientTemplates() []ClientTemplate {
+	out := make([]ClientTemplate, len(clientTemplates))
+	for i, t := range clientTemplates {
+		t.Source, t.fsys = BuiltinTemplateSource, embeddedFS
+		out[i] = t
+	}
+	return out
+}
+
+// templateSource is a directory of user templates added with AddTemplates.
+type templateSource struct {
+	fsys fs.FS
+	dir  string
+	name string // shown as the templates' Source
+}
+
+// AddTemplates registers the *.md templates of dir inside fsys (an embed.FS
+// or os.DirFS) so Templates and Scaffold offer them next to the
+// built-in ones. The directory is read on each lookup: edits show up without
+// restarting. Config.TemplateDir (tinywasm.json's template_dir) is read the
+// same way.
+func (w *WasmClient) AddTemplates(fsys fs.FS, dir string) {
+	w.templateSources = append(w.templateSources, templateSource{fsys: fsys, dir: dir, name: dir})
+}
+
+// Templates returns the built-in templates merged with the user ones: a user
+// template named like a built-in replaces it, the others follow sorted by
+// name. The error names the first template file that could not be loaded.
+func (w *WasmClient) Templates() ([]ClientTemplate, error) {
+	sources := append([]templateSource{}, w.templateSources...)
+	if dir := w.Config.TemplateDir; dir != "" {
+		sources = append([]templateSource{{fsys: os.DirFS(filepath.Join(w.AppRootDir, dir)), dir: ".", name: dir}}, sources...)
+	}
+
+	all := ClientTemplates()
+	index := map[string]int{}
+	for i, t := range all {
+		index[t.Name] = i
+	}
+	var extra []ClientTemplate
+	defined := map[string]string{} // user template name → its file
+	for _, src := range sources {
+		loaded, err := loadClientTemplates(src)
+		if err != nil {
+			return all, err
+		}
+		for _, t := range loaded {
+			file := path.Join(t.Source, path.Base(t.File))
+			if other, dup := defined[t.Name]; dup {
+				return all, Errf("template %q defined twice: %s and %s", t.Name, other, file)
+			}
+			defined[t.Name] = file
+			if i, ok := index[t.Name]; ok {
+				all[i] = t
+				continue
+			}
+			extra = append(extra, t)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Name < extra[j].Name })
+	return append(all, extra...), nil
+}
+
+// FindTemplate returns the template called name ("" selects
+// DefaultClientTemplate) among Templates; the error lists the available names.
+func (w *WasmClient) FindTemplate(name string) (ClientTemplate, error) {
+	if name == "" {
+		name = DefaultClientTemplate
+	}
+	all, err := w.Templates()
+	if err != nil {
+		return ClientTemplate{}, err
+	}
+	names := make([]string, len(all))
+	for i, t := range all {
+		if t.Name == name {
+			return t, nil
+		}
+		names[i] = t.Name
+	}
+	return ClientTemplate{}, Errf("unknown client template %q, available: %s", name, strings.Join(names, ", "))
+}
+
+// LoadClientTemplates reads the *.md templates of dir inside fsys. Each one
+// opens with a front-matter block:
+//
+//	---
+//	name: house
+//	description: team starter with header, router and theme
+//	dependencies: github.com/tinywasm/dom, github.com/acme/ui@v1.4.0
+//	files: client.go, theme.css
+//	---
+//
+// name is required (letters, digits, - and _); dependencies are module paths,
+// with an optional @version (default @latest); files default to the entry point.
+func LoadClientTemplates(fsys fs.FS, dir string) ([]ClientTemplate, error) {
+	return loadClientTemplates(templateSource{fsys: fsys, dir: dir, name: dir})
+}
+
+func loadClientTemplates(src templateSource) ([]ClientTemplate, error) {
+	if _, err := fs.Stat(src.fsys, src.dir); err != nil {
+		return nil, Errf("template directory %s: %w", src.name, err)
+	}
+	files, err := fs.Glob(src.fsys, path.Join(src.dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ClientTemplate, 0, len(files))
+	seen := map[string]string{}
+	for _, file := range files {
+		data, err := fs.ReadFile(src.fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		display := path.Join(src.name, path.Base(file))
+		t, err := parseClientTemplate(string(data))
+		if err != nil {
+			return nil, Errf("%s: %w", display, err)
+		}
+		if other, dup := seen[t.Name]; dup {
+			return nil, Errf("%s: template name %q already used by %s", display, t.Name, other)
+		}
+		seen[t.Name] = display
+		t.File, t.Source, t.fsys = file, src.name, src.fsys
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// parseClientTemplate reads the front-matter of a user template.
+func parseClientTemplate(content string) (ClientTemplate, error) {
+	meta, err := markdown.ParseFrontmatter(content)
+	if err != nil {
+		return ClientTemplate{}, err
+	}
+	t := ClientTemplate{
+		Name:        meta["name"],
+		Description: meta["description"],
+		Modules:     splitList(meta["dependencies"]),
+		Files:       splitList(meta["files"]),
+	}
+	if t.Name == "" {
+		return t, Err("front-matter has no name")
+	}
+	for _, r := range t.Name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return t, Errf("template name %q: use letters, digits, - and _", t.Name)
+		}
+	}
+	if err := checkTemplateFiles(t.Files); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// checkTemplateFiles checks that files are relative paths with one file per
+// language render takes unannotated blocks for (go, js or javascript, css),
+// and that one of them is the .go entry point.
+func checkTemplateFiles(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	byExt := map[string]string{}
+	for _, f := range files {
+		clean := path.Clean(filepath.ToSlash(f))
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return Errf("files: %s must be a path inside the source directory", f)
+		}
+		ext := path.Ext(clean)
+		if ext != ".go" && ext != ".js" && ext != ".css" {
+			return Errf("files: %s: only .go, .js and .css files can be extracted", f)
+		}
+		if other, dup := byExt[ext]; dup {
+			return Errf("files: %s and %s both take the %s code blocks", other, f, ext)
+		}
+		byExt[ext] = f
+	}
+	if _, ok := byExt[".go"]; !ok {
+		return Err("files: no .go file for the entry point")
+	}
+	return nil
+}
+
+// templateFile is one file rendered from a template, relative to SourceDir.
+type templateFile struct {
+	Path    string
+	Content string
+}
+
+// blockLanguages maps a fence language to the extension of the file taking
+// its unannotated blocks.
+var blockLanguages = map[string]string{
+	"go":         ".go",
+	"javascript": ".js",
+	"js":         ".js",
+	"css":        ".css",
+}
+
+// render splits content (placeholders already replaced) into the files the
+// template writes. A fenced block annotated with file=path (```go
+// file=components/header.go) goes to that file; an unannotated block goes to
+// the file of its language in Files, the Go code to the entry point mainFile.
+// Blocks of the same file are joined in order; other languages are ignored.
+func (t ClientTemplate) render(content, mainFile string) ([]templateFile, error) {
+	defaults := map[string]string{".go": mainFile}
+	for _, f := range t.Files {
+		if ext := path.Ext(f); ext != ".go" {
+			defaults[ext] = path.Clean(filepath.ToSlash(f))
+		}
+	}
+
+	var files []templateFile
+	index := map[string]int{}
+	target, inBlock := "", false
+	var block strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if !strings.HasPrefix(trimmed, "```") {
+			if inBlock && target != "" {
+				block.WriteString(line + "\n")
+			}
+			continue
+		}
+		if inBlock {
+			if trimmed != "```" {
+				continue
+			}
+			inBlock = false
+			if target == "" {
+				continue
+			}
+			code := strings.TrimSpace(block.String())
+			block.Reset()
+			if i, ok := index[target]; ok {
+				files[i].Content += "\n" + code + "\n"
+				continue
+			}
+			index[target] = len(files)
+			files = append(files, templateFile{Path: target, Content: code + "\n"})
+			continue
+		}
+
+		inBlock = true
+		info := strings.Fields(strings.TrimPrefix(trimmed, "```"))
+		target = ""
+		if len(info) > 0 {
+			target = defaults[blockLanguages[info[0]]]
+		}
+		for _, attr := range info[min(1, len(info)):] {
+			if file, ok := strings.CutPrefix(attr, "file="); ok {
+				clean := path.Clean(filepath.ToSlash(file))
+				if file == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+					return nil, Errf("file=%s must be a path inside the source directory", file)
+				}
+				target = clean
+			}
+		}
+	}
+	if _, ok := index[mainFile]; !ok {
+		return nil, Err("no go code block for the entry point")
+	}
+	for i := range files {
+		files[i].Path = filepath.FromSlash(files[i].Path)
+	}
+	return files, nil
+}
+
+// read returns the markdown of the template.
+func (t ClientTemplate) read() ([]byte, error) {
+	if t.fsys == nil {
+		return embeddedFS.ReadFile(t.File)
+	}
+	return fs.ReadFile(t.fsys, t.File)
+}
+
+// splitList splits a comma or space separated front-matter value.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+}
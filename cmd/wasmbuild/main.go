@@ -1,25 +1,393 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/tinywasm/client"
 )
 
+// listFlag collects the values of a repeatable flag.
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, " ") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rewrite":
+			runRewrite(os.Args[2:])
+			return
+		case "files":
+			runFiles(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		case "init":
+			runInit(os.Args[2:])
+			return
+		}
+	}
+
 	stdlib := flag.Bool("stdlib", false, "use Go standard compiler instead of TinyGo")
+	onFindings := flag.String("on-findings", "abort", "when the TinyGo pre-flight finds unsupported packages: abort, proceed or large (build with -stdlib)")
+	mode := flag.String("mode", "", "size mode L, M or S, a profile: large/dev, medium/debug, small/prod, or a custom profile of tinywasm.json (default S, or L with -stdlib)")
+	sourceDir := flag.String("src", "", "directory of the main file (default web, or tinywasm.json's source_dir)")
+	mainFile := flag.String("main", "", "main input file inside -src (default client.go, or tinywasm.json's main_file)")
+	outputDir := flag.String("out", "", "directory for the .wasm file and script.js (default web/public, or tinywasm.json's output_dir)")
+	outputName := flag.String("name", "", "name of the .wasm file without extension (default client, or tinywasm.json's output_name)")
+	watch := flag.Bool("watch", false, "keep running and rebuild when a file imported by the main file changes")
+	jsonOut := flag.Bool("json", false, "print a single JSON result object on stdout (logs go to stderr)")
+	maxSize := flag.String("max-size", "", "fail when the .wasm file is larger, eg: 500KB, 1.5MB")
+	reproducible := flag.Bool("reproducible", false, "byte-for-byte repeatable build: -trimpath, no build ID or VCS stamp, pinned GOTOOLCHAIN")
+	toolchain := flag.String("toolchain", "", "GOTOOLCHAIN of -reproducible builds, eg: go1.25.2 (default go.mod's toolchain, or local)")
+	matrix := flag.Bool("matrix", false, "build every mode and tinywasm.json profile side by side (<name>-large.wasm, ...) and print a comparison table; -mode takes a comma-separated subset")
+	var buildArgs, env listFlag
+	flag.Var(&buildArgs, "arg", "extra compiler argument (repeatable), eg: -arg=-ldflags -arg='-X main.version=1'")
+	flag.Var(&env, "env", "extra compiler environment variable KEY=VALUE (repeatable)")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  Compiles web/client.go to web/public/client.wasm and generates web/public/script.js\n\n")
+		fmt.Fprintf(os.Stderr, "  Compiles web/client.go to web/public/client.wasm and generates web/public/script.js\n")
+		fmt.Fprintf(os.Stderr, "  (-src, -main, -out and -name change that layout)\n")
+		fmt.Fprintf(os.Stderr, "  Exit codes: 2 usage, 3 toolchain missing, 4 compile error, 5 budget exceeded, 6 I/O, 7 verify mismatch, 8 TinyGo pre-flight abort\n\n")
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nSubcommands:\n")
+		fmt.Fprintf(os.Stderr, "  rewrite   replace stdlib imports in wasm files with tinywasm equivalents\n")
+		fmt.Fprintf(os.Stderr, "  files     list the files compiled into the wasm binary and flag server-only imports\n")
+		fmt.Fprintf(os.Stderr, "  lint      report direct syscall/js usage with tinywasm/dom replacements\n")
+		fmt.Fprintf(os.Stderr, "  serve     dev server with in-memory builds and live reload\n")
+		fmt.Fprintf(os.Stderr, "  doctor    check the Go/TinyGo toolchain and the project layout, with fix hints\n")
+		fmt.Fprintf(os.Stderr, "  verify    build twice in separate temp copies and compare the binaries\n")
+		fmt.Fprintf(os.Stderr, "  init      generate web/client.go from a template (-list shows them)\n")
 	}
 	flag.Parse()
 
-	err := client.RunWasmBuild(client.WasmBuildArgs{Stdlib: *stdlib})
+	decision, err := client.ParsePreflightDecision(*onFindings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var budget int64
+	if *maxSize != "" {
+		if budget, err = client.ParseByteSize(*maxSize); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if *watch && (*jsonOut || *matrix) {
+		fmt.Fprintln(os.Stderr, "-watch cannot be combined with -json or -matrix")
+		os.Exit(2)
+	}
+	if *matrix && *stdlib {
+		fmt.Fprintln(os.Stderr, "-matrix builds every mode: use -mode to pick them instead of -stdlib")
+		os.Exit(2)
+	}
+
+	args := client.WasmBuildArgs{
+		Stdlib:     *stdlib,
+		OnFindings: decision,
+		Mode:       *mode,
+		SourceDir:  *sourceDir,
+		MainFile:   *mainFile,
+		OutputDir:  *outputDir,
+		OutputName: *outputName,
+		BuildArgs:  buildArgs,
+		Env:        env,
+		MaxSize:    budget,
+		Watch:      *watch,
+
+		Reproducible: *reproducible,
+		Toolchain:    *toolchain,
+	}
+
+	if *jsonOut {
+		args.Log = func(message ...any) { fmt.Fprintln(os.Stderr, message...) }
+	}
+
+	if *matrix {
+		var modes []string
+		if *mode != "" {
+			modes = strings.Split(*mode, ",")
+		}
+		args.Mode = ""
+		report, err := client.RunWasmMatrix(client.WasmMatrixArgs{WasmBuildArgs: args, Modes: modes})
+		switch {
+		case report == nil:
+			fmt.Fprintln(os.Stderr, err)
+		case *jsonOut:
+			printJSON(report)
+		default:
+			fmt.Println(report.Table())
+		}
+		os.Exit(client.ExitCode(err))
+	}
+
+	if *jsonOut {
+		result, err := client.BuildWasm(args)
+		printJSON(result)
+		os.Exit(client.ExitCode(err))
+	}
+
+	if err := client.RunWasmBuild(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+// printJSON writes v as indented JSON on stdout.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func runRewrite(args []string) {
+	fs := flag.NewFlagSet("rewrite", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "print the diff without writing files")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s rewrite:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Rewrites fmt/errors/strings/strconv to github.com/tinywasm/fmt in the wasm files of web/client.go\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if err := client.RunWasmRewrite(client.WasmRewriteArgs{DryRun: *dryRun}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+func runFiles(args []string) {
+	fs := flag.NewFlagSet("files", flag.ExitOnError)
+	stdlib := fs.Bool("stdlib", false, "report for the Go standard compiler (mode L, dev tag) instead of tinywasm.json's mode (default S)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s files:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Lists the packages and files compiled into web/client.go for GOOS=js GOARCH=wasm and the mode's build tags\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if err := client.RunWasmFiles(client.WasmFilesArgs{Stdlib: *stdlib}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	stdlib := fs.Bool("stdlib", false, "lint the files of the Go standard compiler (mode L, dev tag) instead of tinywasm.json's mode (default S)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s lint:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Reports syscall/js imports and calls in the project files of web/client.go with tinywasm/dom replacements\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if err := client.RunWasmLint(client.WasmLintArgs{Stdlib: *stdlib}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	root := fs.String("root", ".", "project root holding go.mod and tinywasm.json")
+	jsonOut := fs.Bool("json", false, "print the checks as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s doctor:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Reports the Go and TinyGo versions, TINYGOROOT, GOOS/GOARCH leaks, go.mod, the main file,\n")
+		fmt.Fprintf(os.Stderr, "  OutputDir permissions, tinywasm.json and VS Code settings; exits 1 when a check fails\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	report, err := client.RunWasmDoctor(client.WasmDoctorArgs{RootDir: *root})
+	if *jsonOut {
+		printJSON(report)
+	} else {
+		fmt.Println(report.Summary())
+	}
+	if err != nil {
+		os.Exit(client.ExitCode(err))
+	}
+}
+
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	stdlib := fs.Bool("stdlib", false, "use Go standard compiler instead of TinyGo")
+	mode := fs.String("mode", "", "size mode L, M or S, a profile: large/dev, medium/debug, small/prod, or a custom profile of tinywasm.json (default S, or L with -stdlib)")
+	sourceDir := fs.String("src", "", "directory of the main file (default web, or tinywasm.json's source_dir)")
+	mainFile := fs.String("main", "", "main input file inside -src (default client.go, or tinywasm.json's main_file)")
+	outputDir := fs.String("out", "", "output directory inside each copy, skipped when copying (default web/public, or tinywasm.json's output_dir)")
+	outputName := fs.String("name", "", "name of the .wasm file without extension (default client, or tinywasm.json's output_name)")
+	toolchain := fs.String("toolchain", "", "GOTOOLCHAIN of both builds, eg: go1.25.2 (default go.mod's toolchain, or local)")
+	keep := fs.Bool("keep", false, "keep the two temporary copies")
+	jsonOut := fs.Bool("json", false, "print the report as JSON (logs go to stderr)")
+	var buildArgs, env listFlag
+	fs.Var(&buildArgs, "arg", "extra compiler argument (repeatable)")
+	fs.Var(&env, "env", "extra compiler environment variable KEY=VALUE (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s verify:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Copies the project into two temp directories, builds each with -reproducible and compares\n")
+		fmt.Fprintf(os.Stderr, "  the binaries, reporting the first differing section; exits 7 when they differ.\n")
+		fmt.Fprintf(os.Stderr, "  Covers modes L and S: mode M keeps debug information naming the source directory\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	verifyArgs := client.WasmVerifyArgs{
+		Keep: *keep,
+		WasmBuildArgs: client.WasmBuildArgs{
+			Stdlib:     *stdlib,
+			Mode:       *mode,
+			SourceDir:  *sourceDir,
+			MainFile:   *mainFile,
+			OutputDir:  *outputDir,
+			OutputName: *outputName,
+			BuildArgs:  buildArgs,
+			Env:        env,
+			Toolchain:  *toolchain,
+		},
+	}
+	if *jsonOut {
+		verifyArgs.Log = func(message ...any) { fmt.Fprintln(os.Stderr, message...) }
+	}
+
+	report, err := client.RunWasmVerify(verifyArgs)
+	switch {
+	case report == nil:
+		fmt.Fprintln(os.Stderr, err)
+	case *jsonOut:
+		printJSON(report)
+	default:
+		fmt.Println(report.Summary())
+	}
+	os.Exit(client.ExitCode(err))
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	stdlib := fs.Bool("stdlib", false, "use Go standard compiler instead of TinyGo")
+	onFindings := fs.String("on-findings", "abort", "when the TinyGo pre-flight finds unsupported packages: abort, proceed or large (build with -stdlib)")
+	mode := fs.String("mode", "", "size mode L, M or S, a profile: large/dev, medium/debug, small/prod, or a custom profile of tinywasm.json (default S, or L with -stdlib)")
+	sourceDir := fs.String("src", "", "directory of the main file (default web, or tinywasm.json's source_dir)")
+	mainFile := fs.String("main", "", "main input file inside -src (default client.go, or tinywasm.json's main_file)")
+	outputDir := fs.String("out", "", "directory of the static files served at / (default web/public, or tinywasm.json's output_dir)")
+	outputName := fs.String("name", "", "name of the .wasm file without extension (default client, or tinywasm.json's output_name)")
+	var buildArgs, env listFlag
+	fs.Var(&buildArgs, "arg", "extra compiler argument (repeatable)")
+	fs.Var(&env, "env", "extra compiler environment variable KEY=VALUE (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s serve:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Serves web/public with web/client.go compiled in memory, rebuilds on change and reloads the page\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	decision, err := client.ParsePreflightDecision(*onFindings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	err = client.RunWasmServe(client.WasmServeArgs{
+		Addr: *addr,
+		WasmBuildArgs: client.WasmBuildArgs{
+			Stdlib:     *stdlib,
+			OnFindings: decision,
+			Mode:       *mode,
+			SourceDir:  *sourceDir,
+			MainFile:   *mainFile,
+			OutputDir:  *outputDir,
+			OutputName: *outputName,
+			BuildArgs:  buildArgs,
+			Env:        env,
+		},
+	})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	template := fs.String("template", client.DefaultClientTemplate, "template of the main file, see -list")
+	list := fs.Bool("list", false, "list the templates and exit")
+	templateDir := fs.String("templates", "", "directory of user templates (default tinywasm.json's template_dir)")
+	skipIDE := fs.Bool("skip-ide", false, "do not write the VS Code GOOS/GOARCH settings")
+	appName := fs.String("app", "", "{{app_name}} of the template (default: last element of the module path)")
+	mountID := fs.String("mount", "", "{{mount_id}} of the template, id of the element the app renders into (default app)")
+	modulePath := fs.String("module", "", "{{module_path}} of the template (default: go.mod's module)")
+	outputName := fs.String("output", "", "{{output_name}} of the template, .wasm file name without extension (default: the client's output name)")
+	dryRun := fs.Bool("dry-run", false, "print the files, go.mod, IDE and compile steps as a diff and a summary without running them")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s init:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  Generates web/client.go (or tinywasm.json's main file) and the other files of a template,\n")
+		fmt.Fprintf(os.Stderr, "  adds the modules it imports to go.mod and compiles it; existing files are never overwritten\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	initArgs := client.WasmInitArgs{
+		Template:      *template,
+		TemplateDir:   *templateDir,
+		SkipIDEConfig: *skipIDE,
+		AppName:       *appName,
+		MountID:       *mountID,
+		ModulePath:    *modulePath,
+		OutputName:    *outputName,
+	}
+	if *list {
+		templates, err := client.RunWasmTemplates(initArgs)
+		for _, t := range templates {
+			fmt.Printf("%-8s %s [%s]\n", t.Name, t.Description, t.Source)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(client.ExitCode(err))
+		}
+		return
+	}
+
+	if *dryRun {
+		plan, err := client.RunWasmInitPlan(initArgs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(client.ExitCode(err))
+		}
+		if diff := plan.Diff(); diff != "" {
+			fmt.Println(diff)
+		}
+		fmt.Println(plan.Summary())
+		return
+	}
+
+	result, err := client.RunWasmInit(initArgs)
+	if result != nil {
+		fmt.Println(result.Summary())
+		if result.BuildError != "" {
+			fmt.Println("⚠️ the generated client does not compile yet: " + result.BuildError)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(client.ExitCode(err))
+	}
+}
@@ -24,13 +24,31 @@ type Config struct {
 
 	// TinyGoCompiler removed: TinyGoCompilerFlag (private) in WasmClient is used instead to avoid confusion
 
+	// TemplateDir is an optional directory of user client templates (relative
+	// to AppRootDir), merged with the built-in ones (see WasmClient.Templates).
+	// e.g. "templates"
+	TemplateDir string
+
 	// gobuild integration fields
-	Callback           func(error)     // Optional callback for async compilation
+	Callback           func(error)     // Optional callback for async compilation: DiskStorage.Compile then builds in the background and reports here
 	CompilingArguments func() []string // Build arguments for compilation (e.g., ldflags)
 	Env                []string        // Environment variables, e.g., []string{"GOOS=js", "TINYGOROOT=/path"}
 
+	// Reproducible makes builds byte-for-byte repeatable across machines and
+	// directories: -trimpath, no build ID or VCS stamp, and GOTOOLCHAIN pinned
+	// to Toolchain (see reproducible.go). Covers modes L and S; TinyGo's debug
+	// information of mode M still names the source directory.
+	Reproducible bool
+	// Toolchain is the GOTOOLCHAIN of reproducible builds, eg: "go1.25.2".
+	// default: go.mod's toolchain directive, else "local" (no automatic switch)
+	Toolchain string
+
 	Database         KeyValueDataBase // Key-Value store for state persistence
 	OnWasmExecChange func()           // Callback for runtime/wasm_exec changes
+
+	// skipProjectFile is set by the wasmbuild commands, which already merged
+	// tinywasm.json with their flags, so New does not apply it again.
+	skipProjectFile bool
 }
 
 // NewConfig creates a WasmClient Config with sensible defaults
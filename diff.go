@@ -0,0 +1,134 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// unifiedDiff returns a unified diff between a and b labelled with the given
+// names, or "" when both contents are equal.
+func unifiedDiff(aName, bName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	aLines := splitLines(string(a))
+	bLines := splitLines(string(b))
+	ops := diffLines(aLines, bLines)
+
+	var out strings.Builder
+	out.WriteString("--- " + aName + "\n")
+	out.WriteString("+++ " + bName + "\n")
+
+	// group ops into hunks separated by more than 2*diffContext unchanged lines
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		hunkStart := start - diffContext
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				break
+			}
+			end = run
+		}
+		hunkEnd := end + diffContext
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		aStart, bStart := ops[hunkStart].aLine, ops[hunkStart].bLine
+		aCount, bCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[hunkStart:hunkEnd] {
+			body.WriteByte(op.kind)
+			body.WriteString(op.text)
+			body.WriteByte('\n')
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		out.WriteString("@@ -" + hunkRange(aStart, aCount) + " +" + hunkRange(bStart, bCount) + " @@\n")
+		out.WriteString(body.String())
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start-1) + ",0"
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added.
+// aLine and bLine are the 1-based line numbers the op starts at in each side.
+type diffOp struct {
+	kind         byte
+	text         string
+	aLine, bLine int
+}
+
+// diffLines computes a line edit script from a to b using the longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', b[j], i + 1, j + 1})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', a[i], i + 1, j + 1})
+			i++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,451 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/tinygo"
+)
+
+// DoctorStatus is the outcome of one doctor check.
+type DoctorStatus string
+
+const (
+	DoctorOK   DoctorStatus = "ok"
+	DoctorWarn DoctorStatus = "warn" // works, but some modes or tools will misbehave
+	DoctorFail DoctorStatus = "fail" // builds will fail until fixed
+)
+
+// DoctorCheck is one line of the doctor report.
+type DoctorCheck struct {
+	Name   string       `json:"name"`   // eg: "tinygo"
+	Status DoctorStatus `json:"status"` // ok, warn or fail
+	Detail string       `json:"detail"` // what was found, eg: "0.39.0 at /usr/local/tinygo/bin/tinygo"
+	Hint   string       `json:"hint,omitempty"`
+}
+
+// DoctorReport is the result of Doctor, one check per toolchain or project item.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// Count returns the number of checks with status.
+func (r *DoctorReport) Count(status DoctorStatus) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary returns one line per check, followed by its fix hint when not ok.
+func (r *DoctorReport) Summary() string {
+	width := 0
+	for _, c := range r.Checks {
+		width = max(width, len(c.Name))
+	}
+	icons := map[DoctorStatus]string{DoctorOK: "✅", DoctorWarn: "⚠️", DoctorFail: "❌"}
+
+	var b strings.Builder
+	for _, c := range r.Checks {
+		b.WriteString(icons[c.Status] + " " + c.Name + strings.Repeat(" ", width-len(c.Name)) + "  " + c.Detail + "\n")
+		if c.Status != DoctorOK && c.Hint != "" {
+			b.WriteString("   → " + c.Hint + "\n")
+		}
+	}
+	b.WriteString(strconv.Itoa(len(r.Checks)) + " checks: " + strconv.Itoa(r.Count(DoctorFail)) + " failed, " + strconv.Itoa(r.Count(DoctorWarn)) + " warnings")
+	return b.String()
+}
+
+// tinyGoMaxGo maps a TinyGo minor version (0.x) to the newest Go minor (1.y)
+// it supports, from the TinyGo release notes. Newer TinyGo releases are not
+// checked.
+var tinyGoMaxGo = map[int]int{30: 21, 31: 22, 32: 22, 33: 23, 34: 23, 35: 24, 36: 24, 37: 24, 38: 25, 39: 25, 40: 25}
+
+// doctorGoEnv is the part of `go env` the doctor reads.
+type doctorGoEnv struct {
+	GOVERSION  string
+	GOOS       string
+	GOARCH     string
+	GOHOSTOS   string
+	GOHOSTARCH string
+	GOMOD      string
+}
+
+// Doctor checks the toolchain and the project layout the client compiles:
+// Go and TinyGo versions and their compatibility, TINYGOROOT, GOOS/GOARCH
+// leaking into the environment, go.mod, the main file and its build
+// constraint, OutputDir permissions, tinywasm.json and the VS Code settings.
+// Every check that is not ok carries a fix hint.
+func (w *WasmClient) Doctor() *DoctorReport {
+	r := &DoctorReport{}
+	add := func(name string, status DoctorStatus, detail, hint string) {
+		r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: detail, Hint: hint})
+	}
+
+	env, goErr := w.doctorGoEnv()
+	if goErr != nil {
+		add("go", DoctorFail, goErr.Error(), "install Go from https://go.dev/dl/ and make sure `go` is on PATH")
+	} else {
+		add("go", DoctorOK, env.GOVERSION+" "+env.GOHOSTOS+"/"+env.GOHOSTARCH, "")
+		w.doctorGOOSLeak(env, add)
+	}
+
+	w.doctorTinyGo(env, goErr == nil, add)
+
+	if goErr == nil {
+		w.doctorModule(env, add)
+	}
+	w.doctorProjectFile(add)
+	w.doctorMainFile(add)
+	w.doctorOutputDir(add)
+	w.doctorVSCode(add)
+	return r
+}
+
+// doctorGoEnv runs `go env` in AppRootDir with the client's environment.
+func (w *WasmClient) doctorGoEnv() (doctorGoEnv, error) {
+	var env doctorGoEnv
+	cmd := exec.Command("go", "env", "-json", "GOVERSION", "GOOS", "GOARCH", "GOHOSTOS", "GOHOSTARCH", "GOMOD")
+	cmd.Dir = w.AppRootDir
+	cmd.Env = append(os.Environ(), w.Config.Env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return env, Errf("go env failed: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := json.Unmarshal(out, &env); err != nil {
+		return env, Errf("go env: %v", err)
+	}
+	return env, nil
+}
+
+// doctorGOOSLeak reports GOOS/GOARCH set to a target other than the host: the
+// builders set js/wasm per compilation, but go test, go run and gopls break.
+func (w *WasmClient) doctorGOOSLeak(env doctorGoEnv, add func(name string, status DoctorStatus, detail, hint string)) {
+	var leaks, hints []string
+	for _, v := range []struct{ key, value, host string }{
+		{"GOOS", env.GOOS, env.GOHOSTOS},
+		{"GOARCH", env.GOARCH, env.GOHOSTARCH},
+	} {
+		if v.value == v.host {
+			continue
+		}
+		leaks = append(leaks, v.key+"="+v.value)
+		switch {
+		case os.Getenv(v.key) != "":
+			hints = append(hints, "unset "+v.key)
+		case containsEnvKey(w.Config.Env, v.key):
+			hints = append(hints, "remove "+v.key+" from Config.Env / tinywasm.json env")
+		default:
+			hints = append(hints, "go env -u "+v.key)
+		}
+	}
+	if len(leaks) == 0 {
+		add("GOOS/GOARCH", DoctorOK, "not overridden (host "+env.GOHOSTOS+"/"+env.GOHOSTARCH+")", "")
+		return
+	}
+	add("GOOS/GOARCH", DoctorWarn, strings.Join(leaks, " ")+" set outside the build",
+		strings.Join(hints, "; ")+": the builders set GOOS=js GOARCH=wasm themselves, a global value breaks go test and go run")
+}
+
+// containsEnvKey reports whether env has a KEY=... entry for key.
+func containsEnvKey(env []string, key string) bool {
+	for _, kv := range env {
+		if k, _, _ := strings.Cut(kv, "="); k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// doctorTinyGo reports the TinyGo install, PATH, TINYGOROOT and whether its
+// version supports the installed Go.
+func (w *WasmClient) doctorTinyGo(env doctorGoEnv, haveGo bool, add func(name string, status DoctorStatus, detail, hint string)) {
+	path, err := tinygo.GetPath()
+	if err != nil {
+		add("tinygo", DoctorWarn, "not installed (needed for modes M and S)",
+			"the first M/S build installs TinyGo "+tinygo.DefaultVersion+" automatically, or install it from https://tinygo.org/getting-started/install/")
+		return
+	}
+	out, err := tinygo.GetVersion()
+	if err != nil {
+		add("tinygo", DoctorFail, path+": "+err.Error(), "reinstall TinyGo: remove "+filepath.Dir(filepath.Dir(path))+" and run a M/S build")
+		return
+	}
+	version := ""
+	if fields := strings.Fields(out); len(fields) >= 3 {
+		version = fields[2]
+	}
+	add("tinygo", DoctorOK, version+" at "+path, "")
+
+	if _, err := exec.LookPath("tinygo"); err != nil {
+		add("tinygo PATH", DoctorWarn, filepath.Dir(path)+" is not on PATH",
+			"add it to PATH (export PATH=$PATH:"+filepath.Dir(path)+"): only this client adds it for its own builds")
+	}
+
+	root := os.Getenv("TINYGOROOT")
+	switch {
+	case root == "":
+		detail := "unset"
+		if r := tinygo.GetRoot(); r != "" {
+			detail += " (builds use " + r + ")"
+		}
+		add("TINYGOROOT", DoctorOK, detail, "")
+	case !isDir(root):
+		add("TINYGOROOT", DoctorFail, root+" does not exist", "unset TINYGOROOT or point it at the TinyGo install ("+filepath.Dir(filepath.Dir(path))+")")
+	case !strings.HasPrefix(path, filepath.Clean(root)+string(filepath.Separator)):
+		add("TINYGOROOT", DoctorWarn, root+" is not the root of "+path, "point TINYGOROOT at "+filepath.Dir(filepath.Dir(path))+" or unset it")
+	default:
+		add("TINYGOROOT", DoctorOK, root, "")
+	}
+
+	if !haveGo {
+		return
+	}
+	tinyMinor, goMinor := versionMinor(version), versionMinor(strings.TrimPrefix(env.GOVERSION, "go"))
+	if maxGo, known := tinyGoMaxGo[tinyMinor]; known && goMinor > maxGo {
+		add("go/tinygo", DoctorFail, "TinyGo "+version+" supports Go up to 1."+strconv.Itoa(maxGo)+", found "+env.GOVERSION,
+			"install TinyGo "+tinygo.DefaultVersion+" (remove "+filepath.Dir(filepath.Dir(path))+" and run a M/S build) or use Go 1."+strconv.Itoa(maxGo))
+		return
+	}
+	if versionLess(version, tinygo.DefaultVersion) {
+		add("go/tinygo", DoctorWarn, "TinyGo "+version+" is older than the "+tinygo.DefaultVersion+" this client installs",
+			"upgrade to TinyGo "+tinygo.DefaultVersion+" to build like CI and other contributors")
+		return
+	}
+	add("go/tinygo", DoctorOK, "TinyGo "+version+" with "+env.GOVERSION, "")
+}
+
+// doctorModule reports the module root, its go directive against the installed
+// Go and a missing go.sum.
+func (w *WasmClient) doctorModule(env doctorGoEnv, add func(name string, status DoctorStatus, detail, hint string)) {
+	if env.GOMOD == "" || env.GOMOD == os.DevNull {
+		add("go.mod", DoctorFail, "no go.mod in "+w.AppRootDir+" or above", "run go mod init <module path> in the project root")
+		return
+	}
+	data, err := os.ReadFile(env.GOMOD)
+	if err != nil {
+		add("go.mod", DoctorFail, err.Error(), "check the file permissions of "+env.GOMOD)
+		return
+	}
+	var module, goVersion string
+	requires := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = fields[1]
+		case "require":
+			requires = true
+		}
+	}
+	detail := module + " at " + env.GOMOD
+	if goVersion != "" {
+		detail += " (go " + goVersion + ")"
+	}
+	if versionLess(strings.TrimPrefix(env.GOVERSION, "go"), goVersion) {
+		add("go.mod", DoctorFail, detail+" needs a newer Go than "+env.GOVERSION, "install Go "+goVersion+" or newer, or lower the go directive")
+		return
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(env.GOMOD), "go.sum")); requires && errors.Is(err, os.ErrNotExist) {
+		add("go.mod", DoctorWarn, detail+", no go.sum", "run go mod tidy")
+		return
+	}
+	add("go.mod", DoctorOK, detail, "")
+}
+
+// doctorProjectFile reports the tinywasm.json state.
+func (w *WasmClient) doctorProjectFile(add func(name string, status DoctorStatus, detail, hint string)) {
+	if err := w.ProjectConfigError(); err != nil {
+		add(ProjectConfigFile, DoctorFail, strings.ReplaceAll(err.Error(), "\n", "; "), "fix the fields listed (see client.ProjectConfig), the file is ignored until then")
+		return
+	}
+	if _, err := os.Stat(filepath.Join(w.AppRootDir, ProjectConfigFile)); err != nil {
+		add(ProjectConfigFile, DoctorOK, "none, using the defaults and Config", "")
+		return
+	}
+	add(ProjectConfigFile, DoctorOK, "valid", "")
+}
+
+// doctorMainFile reports the main file, its package and the modes its build
+// constraint excludes it from.
+func (w *WasmClient) doctorMainFile(add func(name string, status DoctorStatus, detail, hint string)) {
+	rel := w.MainInputFileRelativePath()
+	path := filepath.Join(w.AppRootDir, rel)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		add("main file", DoctorFail, rel+" not found", "create it (CreateDefaultWasmFileClientIfNotExist) or set source_dir/main_file in "+ProjectConfigFile)
+		return
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, src, parser.PackageClauseOnly)
+	if err != nil {
+		add("main file", DoctorFail, err.Error(), "fix the syntax error")
+		return
+	}
+	if f.Name.Name != "main" {
+		add("main file", DoctorFail, rel+" is package "+f.Name.Name, "the wasm entry point must be package main with a func main")
+		return
+	}
+
+	var expr constraint.Expr
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if constraint.IsGoBuild(line) {
+			expr, _ = constraint.Parse(line)
+			break
+		}
+	}
+	if expr == nil {
+		add("main file", DoctorOK, rel+", no build constraint", "")
+		return
+	}
+
+	var excluded []string
+	for _, mode := range []string{w.buildLargeSizeShortcut, w.buildMediumSizeShortcut, w.buildSmallSizeShortcut} {
+		tags := append(w.buildTags(mode), "js", "wasm")
+		if !w.RequiresTinyGo(mode) {
+			tags = append(tags, "gc")
+		}
+		if !expr.Eval(func(tag string) bool { return containsString(tags, tag) }) {
+			excluded = append(excluded, mode)
+		}
+	}
+	detail := rel + ", //go:build " + expr.String()
+	switch len(excluded) {
+	case 0:
+		add("main file", DoctorOK, detail, "")
+	case 3:
+		add("main file", DoctorFail, detail+" excludes it from every mode", "build it for js && wasm, eg: //go:build js && wasm")
+	default:
+		add("main file", DoctorWarn, detail+" excludes it from mode "+strings.Join(excluded, ", "),
+			"those modes compile nothing: relax the constraint or avoid the modes")
+	}
+}
+
+// doctorOutputDir reports whether OutputDir (or the parent it will be created
+// in) is writable.
+func (w *WasmClient) doctorOutputDir(add func(name string, status DoctorStatus, detail, hint string)) {
+	rel := w.Config.OutputDir()
+	dir := filepath.Join(w.AppRootDir, rel)
+	detail := rel
+	for !isDir(dir) {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		detail = rel + " (created on the first build)"
+	}
+	tmp, err := os.CreateTemp(dir, ".wasmbuild-doctor-*")
+	if err != nil {
+		add("output dir", DoctorFail, dir+" is not writable", "chmod u+w "+dir+" or set output_dir in "+ProjectConfigFile)
+		return
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	add("output dir", DoctorOK, detail+" writable", "")
+}
+
+// doctorVSCode reports whether .vscode/settings.json points gopls at js/wasm,
+// without which the editor flags every syscall/js import.
+func (w *WasmClient) doctorVSCode(add func(name string, status DoctorStatus, detail, hint string)) {
+	hint := "call VisualStudioCodeWasmEnvConfig() (run by CreateDefaultWasmFileClientIfNotExist) to set gopls env GOOS=js GOARCH=wasm"
+	rel := filepath.Join(".vscode", "settings.json")
+	data, err := os.ReadFile(filepath.Join(w.AppRootDir, rel))
+	if err != nil {
+		add("vscode", DoctorWarn, rel+" not found", hint)
+		return
+	}
+	var settings struct {
+		Gopls struct {
+			Env map[string]string `json:"env"`
+		} `json:"gopls"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		add("vscode", DoctorWarn, rel+" is not valid JSON: "+err.Error(), "fix the file, "+hint+" would replace it")
+		return
+	}
+	if e := settings.Gopls.Env; e["GOOS"] != "js" || e["GOARCH"] != "wasm" {
+		add("vscode", DoctorWarn, rel+" gopls env is not GOOS=js GOARCH=wasm", hint)
+		return
+	}
+	add("vscode", DoctorOK, rel+" gopls env js/wasm", "")
+}
+
+// isDir reports whether path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// versionNumbers parses "1.25.2" or "0.39.0-dev" into its numbers.
+func versionNumbers(v string) []int {
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		n, err := strconv.Atoi(part[:end])
+		if err != nil {
+			break
+		}
+		nums = append(nums, n)
+		if end < len(part) {
+			break
+		}
+	}
+	return nums
+}
+
+// versionMinor returns the second number of v, -1 when absent.
+func versionMinor(v string) int {
+	if nums := versionNumbers(v); len(nums) >= 2 {
+		return nums[1]
+	}
+	return -1
+}
+
+// versionLess reports whether version a is older than b; false when either
+// cannot be parsed.
+func versionLess(a, b string) bool {
+	na, nb := versionNumbers(a), versionNumbers(b)
+	if len(na) == 0 || len(nb) == 0 {
+		return false
+	}
+	for i := 0; i < max(len(na), len(nb)); i++ {
+		var x, y int
+		if i < len(na) {
+			x = na[i]
+		}
+		if i < len(nb) {
+			y = nb[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
@@ -4,23 +4,15 @@ import (
 	"embed"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/tinywasm/command"
 	. "github.com/tinywasm/fmt"
-	"github.com/tinywasm/markdown"
 )
 
 //go:embed templates/*
 var embeddedFS embed.FS
 
-// templateModules: modules imported by templates/basic_wasm_client.md.
-// Keep in sync with the template's imports.
-var templateModules = []string{
-	"github.com/tinywasm/dom",
-	"github.com/tinywasm/fmt",
-	"github.com/tinywasm/html",
-}
-
 // CreateDefaultWasmFileClientIfNotExist creates a default WASM main.go file from the embedded markdown template
 // It never overwrites an existing file and returns the WasmClient instance for method chaining.
 func (t *WasmClient) CreateDefaultWasmFileClientIfNotExist(skipIDEConfig bool) *WasmClient {
@@ -32,82 +24,42 @@ func (t *WasmClient) CreateDefaultWasmFileClientIfNotExist(skipIDEConfig bool) *
 	// Path to client.go
 	clientPath := filepath.Join(t.AppRootDir, t.Config.SourceDir(), t.MainInputFile)
 	if _, err := os.Stat(clientPath); os.IsNotExist(err) {
-		// Read embedded markdown (no template processing needed - static content)
-		raw, errRead := embeddedFS.ReadFile("templates/basic_wasm_client.md")
-		if errRead != nil {
-			t.Logger("Error reading embedded template:", errRead)
-			return t
-		}
-
-		// Use devflow to extract Go code from markdown
-		writer := func(name string, data []byte) error {
-			if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
-				return err
-			}
-			return os.WriteFile(name, data, 0o644)
-		}
-
-		// Ensure SourceDir exists
-		srcDir := filepath.Join(t.AppRootDir, t.Config.SourceDir())
-		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-			if err := os.MkdirAll(srcDir, 0o755); err != nil {
-				t.Logger("Error creating source directory:", err)
-				return t
-			}
-		}
-
-		m := markdown.New(t.AppRootDir, srcDir, writer).
-			InputByte(raw)
-
-		// Extract to the main file
-		if err := m.Extract(t.MainInputFile); err != nil {
-			t.Logger("Error extracting go code from markdown:", err)
-			return t
-		}
-
-		t.LogSuccessState("Generated WASM source file at", clientPath)
-
-		if !skipIDEConfig {
-			t.VisualStudioCodeWasmEnvConfig()
-		}
-
-		// Ensure dependencies are present before compiling
-		if err := t.ensureTemplateDependencies(); err != nil {
-			t.Logger("Error ensuring template dependencies:", err)
-			t.storageMu.Lock()
-			t.lastBuildError = err
-			t.storageMu.Unlock()
-			return t
-		}
-
-		// Trigger compilation immediately so In-Memory mode has content to serve
-		t.storageMu.RLock()
-		store := t.Storage
-		t.storageMu.RUnlock()
-
-		if store != nil {
-			if err := store.Compile(); err != nil {
-				t.Logger("Error compiling generated client:", err)
-				t.storageMu.Lock()
-				t.lastBuildError = err
-				t.storageMu.Unlock()
-			} else {
-				t.storageMu.Lock()
-				t.lastBuildError = nil
-				t.storageMu.Unlock()
-			}
+		if _, err := t.GenerateWasmClient(DefaultClientTemplate, skipIDEConfig); err != nil {
+			t.Logger(err)
 		}
 	}
 
 	return t
 }
 
-func (t *WasmClient) ensureTemplateDependencies() error {
-	for _, mod := range templateModules {
+// GenerateWasmClient writes the main input file from template ("" selects
+// DefaultClientTemplate) with the default variables, plus the other files the
+// template declares; see Scaffold. It returns the entry point written,
+// relative to AppRootDir, and fails without writing anything when the main
+// input file already exists. ShouldGenerateDefaultFile is not consulted: the
+// call is the user's request.
+func (t *WasmClient) GenerateWasmClient(template string, skipIDEConfig bool) (string, error) {
+	relPath := t.MainInputFileRelativePath()
+	if _, err := os.Stat(filepath.Join(t.AppRootDir, t.Config.SourceDir(), t.MainInputFile)); err == nil {
+		return "", Errf("%s already exists, it is never overwritten", relPath)
+	}
+	if _, err := t.Scaffold(ScaffoldOptions{Template: template, SkipIDEConfig: skipIDEConfig}); err != nil {
+		return relPath, err
+	}
+	return relPath, nil
+}
+
+// ensureTemplateDependencies adds modules to go.mod, at their latest version
+// unless a module carries its own @version.
+func (t *WasmClient) ensureTemplateDependencies(modules []string) error {
+	for _, mod := range modules {
 		t.Logger("Ensuring dependency:", mod)
-		_, err := command.RunInDir(t.AppRootDir, "go", "get", mod+"@latest")
+		if !strings.Contains(mod, "@") {
+			mod += "@latest"
+		}
+		_, err := command.RunInDir(t.AppRootDir, "go", "get", mod)
 		if err != nil {
-			return Errf("failed to add dependency %s: %w. Please run: go get %s@latest", mod, err, mod)
+			return Errf("failed to add dependency %s: %w. Please run: go get %s", mod, err, mod)
 		}
 	}
 	return nil
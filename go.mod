@@ -8,6 +8,7 @@ require (
 	github.com/tinywasm/fmt v0.25.5
 	github.com/tinywasm/gobuild v0.0.26
 	github.com/tinywasm/js v0.0.4
+	github.com/tinywasm/json v0.5.17
 	github.com/tinywasm/markdown v0.0.2
 	github.com/tinywasm/mcp v0.1.20
 	github.com/tinywasm/model v0.1.4
@@ -18,7 +19,6 @@ require (
 
 require (
 	github.com/tinywasm/fetch v0.1.24 // indirect
-	github.com/tinywasm/json v0.5.17 // indirect
 	github.com/tinywasm/time v0.5.0 // indirect
 	github.com/tinywasm/unixid v0.2.23 // indirect
 )
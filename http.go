@@ -7,41 +7,95 @@ import (
 	"github.com/tinywasm/router"
 )
 
-// RegisterRoutes registers the WASM client file route on the provided router.
-// It delegates to the active Storage.
+// wasmServer is a BuildStorage serving the binary itself, so the client's
+// routes can pick the storage on each request.
+type wasmServer interface {
+	serveWasm(ctx router.Context)
+}
+
+// RegisterRoutes registers the WASM client file route, and the page bootstrap
+// when SetBootstrapName was called, on the provided router. The handlers
+// resolve the active Storage on each request, so a SetStorage switch applies
+// to routes already registered. A custom BuildStorage registers its own routes.
 func (w *WasmClient) RegisterRoutes(r router.Router) {
 	w.storageMu.RLock()
-	defer w.storageMu.RUnlock()
-	w.Storage.RegisterRoutes(r)
+	store := w.Storage
+	w.storageMu.RUnlock()
+	if _, ok := store.(wasmServer); !ok {
+		store.RegisterRoutes(r)
+		return
+	}
+
+	routePath := w.wasmRoutePath()
+	r.PublicAsset(routePath, func(ctx router.Context) {
+		w.storageMu.RLock()
+		store := w.Storage
+		w.storageMu.RUnlock()
+		if s, ok := store.(wasmServer); ok {
+			s.serveWasm(ctx)
+			return
+		}
+		ctx.WriteStatus(404)
+	})
+	w.LogSuccessState("http route:", routePath)
+
+	if w.BootstrapName != "" {
+		w.registerBootstrapRoute(r)
+	}
 }
 
 func (s *MemoryStorage) RegisterRoutes(r router.Router) {
 	routePath := s.Client.wasmRoutePath()
+	r.PublicAsset(routePath, s.serveWasm)
+	s.Client.LogSuccessState("http route:", routePath)
 
-	r.PublicAsset(routePath, func(ctx router.Context) {
-		s.Mu.RLock()
-		content := s.WasmContent
-		s.Mu.RUnlock()
+	if s.Client.BootstrapName != "" {
+		s.Client.registerBootstrapRoute(r)
+	}
+}
+
+// serveWasm writes the binary held in memory, gzipped when the client accepts it.
+func (s *MemoryStorage) serveWasm(ctx router.Context) {
+	s.Mu.RLock()
+	content := s.WasmContent
+	s.Mu.RUnlock()
 
+	if len(content) == 0 {
+		ctx.WriteStatus(503)
+		ctx.Write([]byte("WASM compiling..."))
+		return
+	}
+
+	ctx.SetHeader("Content-Type", "application/wasm")
+	ctx.SetHeader("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	// Serve with gzip if client supports it (WASM compresses ~60-70%)
+	if strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") {
+		ctx.SetHeader("Content-Encoding", "gzip")
+		gz, _ := gzip.NewWriterLevel(ctx, gzip.BestCompression)
+		gz.Write(content)
+		gz.Close()
+		return
+	}
+
+	ctx.Write(content)
+}
+
+// registerBootstrapRoute serves the page bootstrap generated for the last compiled binary.
+func (w *WasmClient) registerBootstrapRoute(r router.Router) {
+	routePath := w.bootstrapRoutePath()
+
+	r.PublicAsset(routePath, func(ctx router.Context) {
+		content := w.BootstrapScript()
 		if len(content) == 0 {
 			ctx.WriteStatus(503)
 			ctx.Write([]byte("WASM compiling..."))
 			return
 		}
 
-		ctx.SetHeader("Content-Type", "application/wasm")
+		ctx.SetHeader("Content-Type", "text/javascript")
 		ctx.SetHeader("Cache-Control", "no-cache, no-store, must-revalidate")
-
-		// Serve with gzip if client supports it (WASM compresses ~60-70%)
-		if strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") {
-			ctx.SetHeader("Content-Encoding", "gzip")
-			gz, _ := gzip.NewWriterLevel(ctx, gzip.BestCompression)
-			gz.Write(content)
-			gz.Close()
-			return
-		}
-
 		ctx.Write(content)
 	})
-	s.Client.LogSuccessState("http route:", routePath)
+	w.LogSuccessState("http route:", routePath)
 }
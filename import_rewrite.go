@@ -0,0 +1,730 @@
+package client
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build/constraint"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tinywasmFmt replaces fmt, errors, strings and strconv in wasm code.
+const tinywasmFmt = "github.com/tinywasm/fmt"
+
+// callRewrite translates pkg.Func(args) into its tinywasm/fmt equivalent.
+// arity is the required number of arguments (-1 = any).
+// build returns nil when the call cannot be translated with these arguments.
+type callRewrite struct {
+	arity int
+	build func(f string, args []ast.Expr) ast.Expr
+}
+
+// rename keeps the arguments and calls f.name instead.
+func rename(arity int, name string) callRewrite {
+	return callRewrite{arity, func(f string, args []ast.Expr) ast.Expr {
+		return &ast.CallExpr{Fun: qualified(f, name), Args: args}
+	}}
+}
+
+// convertChain builds f.Convert(args[0]).method(args[1:]...)[.String()].
+func convertChain(arity int, method string, toString bool) callRewrite {
+	return callRewrite{arity, func(f string, args []ast.Expr) ast.Expr {
+		var expr ast.Expr = &ast.CallExpr{Fun: qualified(f, "Convert"), Args: args[:1]}
+		if method != "" {
+			expr = &ast.CallExpr{Fun: &ast.SelectorExpr{X: expr, Sel: ast.NewIdent(method)}, Args: args[1:]}
+		}
+		if toString {
+			expr = &ast.CallExpr{Fun: &ast.SelectorExpr{X: expr, Sel: ast.NewIdent("String")}}
+		}
+		return expr
+	}}
+}
+
+// qualified returns pkg.name, or just name when pkg is dot-imported.
+func qualified(pkg, name string) ast.Expr {
+	if pkg == "." {
+		return ast.NewIdent(name)
+	}
+	return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(name)}
+}
+
+// stdlibRewrites lists, per stdlib import path, the calls translated to tinywasm/fmt.
+// Two are only translated where the semantics hold (see keepReason):
+// fmt.Errorf when its format is a constant without %w, since Errf does not
+// wrap, and errors.New outside package-level vars, since Err returns a pooled
+// *Conv whose message goes through the translator, unfit for sentinel errors.
+var stdlibRewrites = map[string]map[string]callRewrite{
+	"fmt": {
+		"Sprintf": rename(-1, "Sprintf"),
+		"Printf":  rename(-1, "Printf"),
+		"Println": rename(-1, "Println"),
+		"Fprintf": rename(-1, "Fprintf"),
+		"Sscanf":  rename(-1, "Sscanf"),
+		"Errorf":  rename(-1, "Errf"),
+		"Sprint":  rename(1, "Sprint"),
+	},
+	"errors": {
+		"New": rename(1, "Err"),
+	},
+	"strings": {
+		"Contains":   rename(2, "Contains"),
+		"HasPrefix":  rename(2, "HasPrefix"),
+		"HasSuffix":  rename(2, "HasSuffix"),
+		"Index":      rename(2, "Index"),
+		"LastIndex":  rename(2, "LastIndex"),
+		"Count":      rename(2, "Count"),
+		"Split":      rename(2, "Split"),
+		"Repeat":     rename(2, "Repeat"),
+		"ReplaceAll": rename(3, "ReplaceAll"),
+		"Replace":    rename(4, "ReplaceN"),
+		"Join":       rename(2, "JoinSlice"),
+		"ToUpper":    convertChain(1, "ToUpper", true),
+		"ToLower":    convertChain(1, "ToLower", true),
+		"TrimSpace":  convertChain(1, "TrimSpace", true),
+		"TrimPrefix": convertChain(2, "TrimPrefix", true),
+		"TrimSuffix": convertChain(2, "TrimSuffix", true),
+	},
+	"strconv": {
+		"Itoa":      convertChain(1, "", true),
+		"Atoi":      convertChain(1, "Int", false),
+		"ParseBool": convertChain(1, "Bool", false),
+		"Quote":     convertChain(1, "Quote", true),
+		"ParseFloat": {2, func(f string, args []ast.Expr) ast.Expr {
+			if lit, ok := args[1].(*ast.BasicLit); !ok || lit.Value != "64" {
+				return nil
+			}
+			return convertChain(1, "Float64", false).build(f, args[:1])
+		}},
+	},
+}
+
+// stdlibManual lists stdlib packages whose tinywasm replacement has a different
+// API: their uses are only reported, never rewritten.
+var stdlibManual = map[string]string{
+	"encoding/json": "github.com/tinywasm/json (Encode/Decode on model.Encodable/Decodable)",
+	"time":          "github.com/tinywasm/time (int64 unix nano timestamps)",
+	"net/http":      "github.com/tinywasm/fetch (Get/Post/Put/Delete builders)",
+}
+
+// RewrittenFile is one file changed (or to be changed in dry-run) by RewriteStdlibImports.
+type RewrittenFile struct {
+	Path       string // relative to AppRootDir
+	Translated int    // number of call sites translated
+	Diff       string // unified diff of the change
+}
+
+// UntranslatedUse is a stdlib use RewriteStdlibImports left for a manual fix.
+type UntranslatedUse struct {
+	Path       string // relative to AppRootDir
+	Line       int
+	Expr       string // eg: "strings.Fields", "json.Marshal"
+	Suggestion string
+}
+
+// RewriteReport is the result of RewriteStdlibImports.
+type RewriteReport struct {
+	DryRun       bool
+	Files        []RewrittenFile
+	Untranslated []UntranslatedUse
+	Modules      []string // modules the rewritten code requires, eg: github.com/tinywasm/fmt
+}
+
+// Diff returns the unified diff of every rewritten file.
+func (r *RewriteReport) Diff() string {
+	var b strings.Builder
+	for _, f := range r.Files {
+		b.WriteString(f.Diff)
+	}
+	return b.String()
+}
+
+// Summary returns a short human readable description of the rewrite.
+func (r *RewriteReport) Summary() string {
+	var b strings.Builder
+	verb := "Rewrote"
+	if r.DryRun {
+		verb = "Would rewrite"
+	}
+	b.WriteString(verb + " " + strconv.Itoa(len(r.Files)) + " file(s)")
+	for _, f := range r.Files {
+		b.WriteString("\n  " + f.Path + ": " + strconv.Itoa(f.Translated) + " call(s)")
+	}
+	if len(r.Untranslated) > 0 {
+		b.WriteString("\n" + strconv.Itoa(len(r.Untranslated)) + " use(s) need a manual change:")
+		for _, u := range r.Untranslated {
+			b.WriteString("\n  " + u.Path + ":" + strconv.Itoa(u.Line) + " " + u.Expr)
+			if u.Suggestion != "" {
+				b.WriteString(" → " + u.Suggestion)
+			}
+		}
+	}
+	for _, mod := range r.Modules {
+		b.WriteString("\nRequires module: go get " + mod + "@latest")
+	}
+	return b.String()
+}
+
+// RewriteStdlibImports replaces fmt, errors, strings and strconv with
+// github.com/tinywasm/fmt in the wasm-tagged files of the wasm entry's import
+// closure that belong to the main module, translating the common call sites.
+// A stdlib import is only replaced in a file when every use of it could be
+// translated; the remaining uses (and encoding/json, time, net/http, whose
+// replacements have a different API) are returned as Untranslated.
+// With dryRun no file is written and the report only carries the diffs.
+func (w *WasmClient) RewriteStdlibImports(dryRun bool) (*RewriteReport, error) {
+	files, err := w.wasmTaggedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RewriteReport{DryRun: dryRun}
+	for _, path := range files {
+		rel := w.relPath(path)
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		out, translated, untranslated, err := rewriteFile(rel, src)
+		if err != nil {
+			return nil, err
+		}
+		report.Untranslated = append(report.Untranslated, untranslated...)
+		if translated == 0 {
+			continue
+		}
+
+		report.Files = append(report.Files, RewrittenFile{
+			Path:       rel,
+			Translated: translated,
+			Diff:       unifiedDiff("a/"+rel, "b/"+rel, src, out),
+		})
+		if !dryRun {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(report.Files) > 0 && !w.moduleRequires(tinywasmFmt) {
+		report.Modules = append(report.Modules, tinywasmFmt)
+	}
+	return report, nil
+}
+
+// wasmTaggedFiles returns the absolute paths of the main module's Go files in
+// the TinyGo import graph whose build constraint or name restricts them to wasm.
+func (w *WasmClient) wasmTaggedFiles() ([]string, error) {
+	g, err := w.loadPackageGraph(w.buildSmallSizeShortcut)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range g.Order {
+		p := g.Packages[path]
+		if p.Standard || (p.Module != nil && !p.Module.Main) || p.Dir == "" {
+			continue
+		}
+		for _, name := range p.GoFiles {
+			full := filepath.Join(p.Dir, name)
+			if wasmOnlyFile(full, g.Tags) {
+				files = append(files, full)
+			}
+		}
+	}
+	return files, nil
+}
+
+// wasmOnlyFile reports whether a file is only compiled for js/wasm: by a
+// _js.go/_wasm.go name or a //go:build line that excludes it without js/wasm.
+func wasmOnlyFile(path string, tags []string) bool {
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, "_js.go") || strings.HasSuffix(name, "_wasm.go") || strings.HasSuffix(name, "_js_wasm.go") {
+		return true
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if !constraint.IsGoBuild(line) {
+			continue
+		}
+		expr, err := constraint.Parse(line)
+		if err != nil {
+			return false
+		}
+		withoutWasm := expr.Eval(func(tag string) bool {
+			return containsString(tags, tag)
+		})
+		return !withoutWasm
+	}
+	return false
+}
+
+// moduleRequires reports whether go.mod at AppRootDir already requires mod.
+func (w *WasmClient) moduleRequires(mod string) bool {
+	return w.requiredVersion(mod) != ""
+}
+
+// requiredVersion returns the version of mod required by go.mod at
+// AppRootDir, "" when it is not required.
+func (w *WasmClient) requiredVersion(mod string) string {
+	data, err := os.ReadFile(filepath.Join(w.AppRootDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require"))
+		if len(fields) > 1 && fields[0] == mod {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// importUse tracks one stdlib import of a file being rewritten.
+type importUse struct {
+	spec      *ast.ImportSpec
+	path      string
+	local     string // name used in the file
+	calls     []*ast.CallExpr
+	replace   map[*ast.CallExpr]ast.Expr
+	manual    []ast.Node // uses that cannot be translated
+	reasons   map[ast.Node]string
+	rewritten bool
+}
+
+// rewriteFile translates the stdlib uses of one file and returns the new source.
+func rewriteFile(rel string, src []byte) (out []byte, translated int, untranslated []UntranslatedUse, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, rel, src, parser.ParseComments)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	uses := map[string]*importUse{} // by local name
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		_, auto := stdlibRewrites[path]
+		_, manual := stdlibManual[path]
+		if !auto && !manual {
+			continue
+		}
+		local := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+		if local == "_" {
+			continue
+		}
+		if local == "." {
+			untranslated = append(untranslated, UntranslatedUse{
+				Path: rel, Line: fset.Position(spec.Pos()).Line, Expr: `. "` + path + `"`,
+				Suggestion: "dot imports are not rewritten automatically",
+			})
+			continue
+		}
+		uses[local] = &importUse{spec: spec, path: path, local: local, replace: map[*ast.CallExpr]ast.Expr{}, reasons: map[ast.Node]string{}}
+	}
+	if len(uses) == 0 {
+		return src, 0, untranslated, nil
+	}
+
+	// calls assigned to package-level vars, eg: var ErrX = errors.New("x")
+	sentinels := map[*ast.CallExpr]bool{}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+			for _, s := range gen.Specs {
+				for _, v := range s.(*ast.ValueSpec).Values {
+					if call, ok := v.(*ast.CallExpr); ok {
+						sentinels[call] = true
+					}
+				}
+			}
+		}
+	}
+
+	// every pkg.X reference of a tracked import is either a translatable call or manual
+	calls := map[*ast.SelectorExpr]*ast.CallExpr{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+				calls[sel] = call
+			}
+		}
+		return true
+	})
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil { // Obj != nil: a local declaration shadows the import
+			return true
+		}
+		use := uses[ident.Name]
+		if use == nil {
+			return true
+		}
+		call := calls[sel]
+		rule, known := stdlibRewrites[use.path][sel.Sel.Name]
+		if call == nil || !known || (rule.arity >= 0 && (len(call.Args) != rule.arity || call.Ellipsis.IsValid())) {
+			use.manual = append(use.manual, sel)
+			return true
+		}
+		if reason := keepReason(use.path, sel.Sel.Name, call, sentinels); reason != "" {
+			use.manual = append(use.manual, sel)
+			use.reasons[sel] = reason
+			return true
+		}
+		use.calls = append(use.calls, call)
+		return true
+	})
+
+	// tinywasm/fmt is imported as "fmt" unless a stdlib fmt import stays in the file
+	fmtName := "fmt"
+	for _, use := range uses {
+		if use.path == "fmt" && (len(use.manual) > 0 || use.local != "fmt") {
+			fmtName = "tfmt"
+		}
+	}
+	for _, existing := range file.Imports {
+		if p, _ := strconv.Unquote(existing.Path.Value); p == tinywasmFmt {
+			fmtName = "fmt"
+			if existing.Name != nil {
+				fmtName = existing.Name.Name
+			}
+		}
+	}
+
+	for _, use := range uses {
+		if _, auto := stdlibRewrites[use.path]; !auto || len(use.manual) > 0 {
+			continue
+		}
+		for _, call := range use.calls {
+			sel := call.Fun.(*ast.SelectorExpr)
+			expr := stdlibRewrites[use.path][sel.Sel.Name].build(fmtName, call.Args)
+			if expr == nil {
+				use.manual = append(use.manual, sel)
+				continue
+			}
+			use.replace[call] = expr
+		}
+		if len(use.manual) == 0 {
+			use.rewritten = true
+			translated += len(use.replace)
+		}
+	}
+
+	for _, use := range uses {
+		if use.rewritten {
+			continue
+		}
+		suggestion := stdlibManual[use.path]
+		if suggestion == "" {
+			suggestion = tinywasmFmt
+		}
+		for _, node := range use.manual {
+			u := UntranslatedUse{
+				Path:       rel,
+				Line:       fset.Position(node.Pos()).Line,
+				Expr:       use.local + "." + node.(*ast.SelectorExpr).Sel.Name,
+				Suggestion: suggestion,
+			}
+			if reason := use.reasons[node]; reason != "" {
+				u.Suggestion = reason
+			}
+			untranslated = append(untranslated, u)
+		}
+	}
+	if translated == 0 {
+		return src, 0, untranslated, nil
+	}
+
+	// apply the call replacements
+	replacements := map[*ast.CallExpr]ast.Expr{}
+	for _, use := range uses {
+		if use.rewritten {
+			for call, expr := range use.replace {
+				replacements[call] = expr
+			}
+		}
+	}
+	replaceCalls(file, replacements)
+
+	// a use left in a position replaceCalls does not rewrite would break the
+	// build once the import is gone: keep the file untouched and report it
+	if left := remainingUses(file, uses); len(left) > 0 {
+		for _, sel := range left {
+			untranslated = append(untranslated, UntranslatedUse{
+				Path:       rel,
+				Line:       fset.Position(sel.Pos()).Line,
+				Expr:       sel.X.(*ast.Ident).Name + "." + sel.Sel.Name,
+				Suggestion: tinywasmFmt,
+			})
+		}
+		return src, 0, untranslated, nil
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, 0, nil, err
+	}
+
+	removed := map[string]bool{}
+	for _, use := range uses {
+		if use.rewritten {
+			removed[use.spec.Path.Value] = true
+		}
+	}
+	out, err = swapImports(buf.Bytes(), removed, fmtName)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return out, translated, untranslated, nil
+}
+
+// swapImports removes the imports whose quoted path is in removed and adds
+// tinywasm/fmt (as name) in its own group, editing the source text so the
+// remaining imports keep their layout and comments.
+func swapImports(src []byte, removed map[string]bool, name string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := strconv.Quote(tinywasmFmt)
+	if name != "fmt" {
+		spec = name + " " + spec
+	}
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == tinywasmFmt {
+			spec = "" // already imported
+		}
+	}
+
+	lines := strings.Split(string(src), "\n")
+	drop := map[int]bool{}
+	insertAfter, insertLine := -1, ""
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		kept := 0
+		for _, s := range gen.Specs {
+			imp := s.(*ast.ImportSpec)
+			if removed[imp.Path.Value] {
+				drop[fset.Position(imp.Pos()).Line-1] = true
+			} else {
+				kept++
+			}
+		}
+		if spec == "" || insertAfter >= 0 {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			insertAfter = fset.Position(gen.Rparen).Line - 2
+			insertLine = "\t" + spec
+			if kept > 0 {
+				insertLine = "\n" + insertLine
+			}
+		} else {
+			insertAfter = fset.Position(gen.Pos()).Line - 1
+			insertLine = "import " + spec
+		}
+	}
+
+	var out []string
+	for i, line := range lines {
+		if !drop[i] {
+			out = append(out, line)
+		}
+		if i == insertAfter {
+			out = append(out, insertLine)
+		}
+	}
+	return format.Source([]byte(strings.Join(out, "\n")))
+}
+
+// keepReason returns why a call with a rewrite rule must stay on the stdlib,
+// "" when it can be translated.
+func keepReason(path, name string, call *ast.CallExpr, sentinels map[*ast.CallExpr]bool) string {
+	switch {
+	case path == "fmt" && name == "Errorf":
+		if len(call.Args) == 0 {
+			return "keep fmt.Errorf: no format"
+		}
+		format, ok := constantString(call.Args[0])
+		if !ok {
+			return "keep fmt.Errorf: the format is not a constant and may hold %w, which Errf does not wrap"
+		}
+		if hasWrapVerb(format) {
+			return "keep fmt.Errorf: Errf does not wrap %w, errors.Is/As would stop matching"
+		}
+	case path == "errors" && name == "New" && sentinels[call]:
+		return "keep errors.New for package-level errors: Err returns a pooled *Conv with a translated message"
+	}
+	return ""
+}
+
+// constantString returns the value of a string literal or of a constant
+// declared as one in the file.
+func constantString(e ast.Expr) (string, bool) {
+	if ident, ok := e.(*ast.Ident); ok && ident.Obj != nil && ident.Obj.Kind == ast.Con {
+		spec, ok := ident.Obj.Decl.(*ast.ValueSpec)
+		if !ok {
+			return "", false
+		}
+		for i, name := range spec.Names {
+			if name.Name == ident.Name && i < len(spec.Values) {
+				e = spec.Values[i]
+			}
+		}
+	}
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// hasWrapVerb reports whether a printf format uses %w, with or without
+// flags and argument index, eg: "%w", "%[2]w".
+func hasWrapVerb(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i]) >= 0; i++ {
+		}
+		if i < len(format) && format[i] == 'w' {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceCalls substitutes every call expression found in replacements.
+func replaceCalls(file *ast.File, replacements map[*ast.CallExpr]ast.Expr) {
+	var visit func(n ast.Node) bool
+	replace := func(e ast.Expr) ast.Expr {
+		if call, ok := e.(*ast.CallExpr); ok {
+			if expr, found := replacements[call]; found {
+				ast.Inspect(expr, visit)
+				return expr
+			}
+		}
+		return e
+	}
+	visit = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			x.Fun = replace(x.Fun)
+			for i := range x.Args {
+				x.Args[i] = replace(x.Args[i])
+			}
+		case *ast.ExprStmt:
+			x.X = replace(x.X)
+		case *ast.AssignStmt:
+			for i := range x.Rhs {
+				x.Rhs[i] = replace(x.Rhs[i])
+			}
+		case *ast.ReturnStmt:
+			for i := range x.Results {
+				x.Results[i] = replace(x.Results[i])
+			}
+		case *ast.ValueSpec:
+			for i := range x.Values {
+				x.Values[i] = replace(x.Values[i])
+			}
+		case *ast.BinaryExpr:
+			x.X = replace(x.X)
+			x.Y = replace(x.Y)
+		case *ast.UnaryExpr:
+			x.X = replace(x.X)
+		case *ast.ParenExpr:
+			x.X = replace(x.X)
+		case *ast.SelectorExpr:
+			x.X = replace(x.X)
+		case *ast.IndexExpr:
+			x.X = replace(x.X)
+			x.Index = replace(x.Index)
+		case *ast.SliceExpr:
+			x.X = replace(x.X)
+		case *ast.KeyValueExpr:
+			x.Value = replace(x.Value)
+		case *ast.CompositeLit:
+			for i := range x.Elts {
+				x.Elts[i] = replace(x.Elts[i])
+			}
+		case *ast.IfStmt:
+			x.Cond = replace(x.Cond)
+		case *ast.SwitchStmt:
+			x.Tag = replace(x.Tag)
+		case *ast.ForStmt:
+			x.Cond = replace(x.Cond)
+		case *ast.RangeStmt:
+			x.X = replace(x.X)
+		case *ast.SendStmt:
+			x.Value = replace(x.Value)
+		case *ast.GoStmt:
+			if call, ok := replace(x.Call).(*ast.CallExpr); ok {
+				x.Call = call
+			}
+		case *ast.DeferStmt:
+			if call, ok := replace(x.Call).(*ast.CallExpr); ok {
+				x.Call = call
+			}
+		case *ast.StarExpr:
+			x.X = replace(x.X)
+		case *ast.TypeAssertExpr:
+			x.X = replace(x.X)
+		case *ast.CaseClause:
+			for i := range x.List {
+				x.List[i] = replace(x.List[i])
+			}
+		}
+		return true
+	}
+	ast.Inspect(file, visit)
+}
+
+// remainingUses returns the references to rewritten imports still present in file.
+func remainingUses(file *ast.File, uses map[string]*importUse) []*ast.SelectorExpr {
+	var left []*ast.SelectorExpr
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || ident.NamePos == token.NoPos {
+			return true
+		}
+		if use := uses[ident.Name]; use != nil && use.rewritten {
+			left = append(left, sel)
+		}
+		return true
+	})
+	return left
+}
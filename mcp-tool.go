@@ -1,34 +1,389 @@
 package client
 
 import (
+	stdjson "encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/tinywasm/context"
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/json"
 	"github.com/tinywasm/mcp"
+	"github.com/tinywasm/model"
 )
 
-// GetMCPTools returns metadata for all WasmClient MCP tools
+// GetMCPTools returns metadata for all WasmClient MCP tools. The mode
+// arguments and descriptions follow the current shortcuts and the last known
+// size of each mode, so call it again after SetBuildShortcuts.
 func (w *WasmClient) GetMCPTools() []mcp.Tool {
+	large, medium, small := w.buildLargeSizeShortcut, w.buildMediumSizeShortcut, w.buildSmallSizeShortcut
+	shortcuts := large + ", " + medium + " or " + small
+	modes, profileNote := shortcuts, ""
+	if profiles := w.profileNames(); len(profiles) > 0 {
+		modes = large + ", " + medium + ", " + small + " or a tinywasm.json profile (" + strings.Join(profiles, ", ") + ")"
+		profileNote = "Or a tinywasm.json profile (" + strings.Join(profiles, ", ") + "): it compiles with its mode plus its own arguments and env. "
+	}
+	current := w.Value()
+	if profile := w.activeProfile(); profile != "" {
+		current += " (profile " + profile + ")"
+	}
 	return []mcp.Tool{
 		{
 			Name: "wasm_set_mode",
 			Description: "Change WebAssembly compilation mode for the Go frontend. " +
-				"L=LARGE (Go std, ~2MB, full features), " +
-				"M=MEDIUM (TinyGo debug, ~500KB, most features), " +
-				"S=SMALL (TinyGo compact, ~200KB, minimal). " +
-				"Use single letter shortcuts: L, M, or S.",
-			Args:     new(SetModeArgs),
+				large + "=LARGE (Go std, full features, " + w.modeSizeLabel(large) + "), " +
+				medium + "=MEDIUM (TinyGo debug, most features, " + w.modeSizeLabel(medium) + "), " +
+				small + "=SMALL (TinyGo compact, minimal, " + w.modeSizeLabel(small) + "). " +
+				"Current mode: " + current + ". Use the shortcuts: " + shortcuts + ". " + profileNote +
+				"Switching to " + medium + "/" + small + " first checks the imports for packages TinyGo cannot compile; " +
+				"on_findings chooses what to do if any are found: " +
+				"abort (default, keep the current mode), proceed (compile anyway) or large (use " + large + ").",
+			Args:     w.modeArgs(new(SetModeArgs)),
 			Resource: "wasm",
 			Action:   'u',
 			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
 				var args SetModeArgs
-				if err := req.Bind(&args); err != nil {
+				if err := req.Bind(w.modeArgs(&args)); err != nil {
+					return nil, err
+				}
+				if err := w.ValidateMode(args.Mode); err != nil {
 					return nil, err
 				}
 
-				// Domain-specific logic: Change WASM compilation mode
-				// Messages flow through w.Logger() which is captured by mcpserve
-				w.Change(args.Mode)
-				return mcp.Text("Compilation mode changed to " + args.Mode), nil
+				decision, err := ParsePreflightDecision(args.OnFindings)
+				if err != nil {
+					return nil, err
+				}
+
+				// Domain-specific logic: Change WASM compilation mode. Its steps
+				// go to OnProgress.
+				applied, report, err := w.change(args.Mode, func(string, *TinyGoReport) PreflightDecision {
+					return decision
+				})
+				if applied == "" && report != nil && err == nil {
+					return mcp.Text("Compilation mode not changed: TinyGo pre-flight found unsupported packages " +
+						"(retry with on_findings=proceed or large).\n" + report.Summary()), nil
+				}
+				if applied == "" {
+					return nil, Errf("compilation mode not changed: %w", err)
+				}
+				if err != nil {
+					return nil, Errf("compilation mode changed to %s but the build failed (see wasm_build_status): %w", applied, err)
+				}
+				msg := "Compilation mode changed to " + applied
+				if want, profile, _ := w.resolveMode(args.Mode); applied != want {
+					msg = "Compilation mode set to " + applied + " instead of " + args.Mode
+				} else if profile != "" {
+					msg += " (profile " + profile + ")"
+				}
+				status := w.BuildStatus()
+				if status.Size > 0 {
+					msg += " (" + formatBytes(status.Size) + ")"
+				}
+				if report != nil {
+					msg += ".\n" + report.Summary()
+				}
+				return mcp.Text(msg), nil
+			},
+		},
+		{
+			Name: "wasm_build_status",
+			Description: "Report the WebAssembly build state as JSON: current mode (" + shortcuts + "), compiler, storage " +
+				"(In-Memory or External), state of the last build (ok, failed, or none if nothing was compiled yet), " +
+				"its error and compiler diagnostics (file, line, column, message), binary size in bytes, " +
+				"when it finished (built_at, RFC 3339) and how long it took (duration_ms). " +
+				"Check it after wasm_set_mode or a source edit to see whether the build worked.",
+			Resource: "wasm",
+			Action:   'r',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				return buildStatusResult(w.BuildStatus())
+			},
+		},
+		{
+			Name: "wasm_compile",
+			Description: "Compile the WebAssembly frontend now and wait for the result (same JSON as wasm_build_status). " +
+				"Without mode it rebuilds the current mode and updates the served binary, as saving a file does. " +
+				"With mode (" + modes + ") different from the current one it only checks that mode compiles: " +
+				"the current mode and the served binary are left unchanged. " +
+				"timeout_seconds bounds the wait (default 120); a build still running then is cancelled. " +
+				"On state=failed fix the reported diagnostics (file, line, column, message) and compile again.",
+			Args:     w.modeArgs(new(CompileArgs)),
+			Resource: "wasm",
+			Action:   'u',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				var args CompileArgs
+				if err := req.Bind(w.modeArgs(&args)); err != nil {
+					return nil, err
+				}
+				status, err := w.CompileAndWait(args.Mode, time.Duration(args.TimeoutSeconds)*time.Second)
+				if err != nil {
+					return nil, err
+				}
+				return buildStatusResult(status)
+			},
+		},
+		{
+			Name: "wasm_size_report",
+			Description: "Explain the size of the WebAssembly binary: total and gzip bytes, bytes per wasm section " +
+				"and code bytes per Go package (largest first, top 20), as compact JSON followed by a summary. " +
+				"Without mode it inspects the binary served for the current mode; " +
+				"with mode (" + modes + ") it compiles that mode in memory first without switching to it. " +
+				"Per-package sizes need a name section: " + small + " builds (TinyGo -no-debug) only report sections.",
+			Args:     w.modeArgs(new(SizeReportArgs)),
+			Resource: "wasm",
+			Action:   'r',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				var args SizeReportArgs
+				if err := req.Bind(w.modeArgs(&args)); err != nil {
+					return nil, err
+				}
+				report, err := w.SizeReport(args.Mode, 0)
+				if err != nil {
+					return nil, err
+				}
+				report = report.Top(20)
+				return jsonSummaryResult(report, report.Summary())
+			},
+		},
+		{
+			Name: "wasm_tinygo_check",
+			Description: "Check whether the WebAssembly frontend compiles with TinyGo (modes " + medium + " and " + small + "): " +
+				"packages TinyGo cannot compile (unsupported) or that bloat the binary (discouraged), " +
+				"each with the import chain that pulls it in and the tinywasm replacement, " +
+				"plus direct syscall/js uses; compact JSON (compatible, findings, syscall_js) followed by a summary.",
+			Resource: "wasm",
+			Action:   'r',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				report, err := w.AnalyzeTinyGoCompatibility()
+				if err != nil {
+					return nil, err
+				}
+				result := struct {
+					Compatible bool `json:"compatible"`
+					*TinyGoReport
+				}{report.Compatible(), report}
+				return jsonSummaryResult(result, report.Summary())
+			},
+		},
+		{
+			Name: "wasm_lint_syscall_js",
+			Description: "Report direct syscall/js usage (imports, js.Global().Get(\"document\"), js.FuncOf, ...) " +
+				"in the project files compiled into the WebAssembly binary for the current mode, " +
+				"with the github.com/tinywasm/dom replacement for each one.",
+			Resource: "wasm",
+			Action:   'r',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				usages, err := w.LintSyscallJs()
+				if err != nil {
+					return nil, err
+				}
+				if len(usages) == 0 {
+					return mcp.Text("No direct syscall/js usage"), nil
+				}
+				return mcp.Text(strconv.Itoa(len(usages)) + " direct syscall/js uses (tinywasm/dom is the only DOM library):" + syscallJsSummary(usages)), nil
+			},
+		},
+		{
+			Name: "wasm_project_layout",
+			Description: "Report where the WebAssembly frontend lives and how it is served, as compact JSON followed by a summary: " +
+				"project root, source_dir, main_file (the Go entry point) and whether it exists, " +
+				"output_file (where disk storage writes the .wasm), the URL route of the binary, " +
+				"storage (memory or disk), the current mode and tinywasm.json when present. " +
+				"Call it first to find the files to edit.",
+			Resource: "wasm",
+			Action:   'r',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				layout := w.Layout()
+				return jsonSummaryResult(layout, layout.Summary())
 			},
 		},
+		{
+			Name: "wasm_generate_client",
+			Description: "Create the Go entry point of the WebAssembly frontend (" + w.MainInputFileRelativePath() + ") from a template, " +
+				"plus the other files the template declares, add the modules it imports to go.mod and compile it. " +
+				"template is one of: " + w.clientTemplatesLabel() + "; default " + DefaultClientTemplate + ". " +
+				"app_name and mount_id fill the template's {{app_name}} and {{mount_id}} (default: last element of the module path and \"app\"). " +
+				"Existing files are never overwritten: they are skipped and reported, the others are still written. " +
+				"skip_ide_config leaves the editor's GOOS/GOARCH settings alone. " +
+				"Returns the scaffold result JSON (template, vars, created, skipped, modules), a summary and the build status JSON. " +
+				"dry_run runs nothing and returns the plan JSON (files to create and skip, go get commands, IDE config change, compile), " +
+				"a summary and the unified diff of the files.",
+			Args:     new(GenerateClientArgs),
+			Resource: "wasm",
+			Action:   'c',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				var args GenerateClientArgs
+				if err := req.Bind(&args); err != nil {
+					return nil, err
+				}
+				opts := ScaffoldOptions{
+					Template:      args.Template,
+					Vars:          TemplateVars{AppName: args.AppName, MountID: args.MountId},
+					SkipIDEConfig: args.SkipIdeConfig,
+				}
+				if args.DryRun {
+					plan, err := w.PlanScaffold(opts)
+					if err != nil {
+						return nil, err
+					}
+					data, err := stdjson.Marshal(plan)
+					if err != nil {
+						return nil, err
+					}
+					return textResult(string(data), plan.Summary(), plan.Diff())
+				}
+				result, err := w.Scaffold(opts)
+				if err != nil {
+					return nil, err
+				}
+				data, err := stdjson.Marshal(result)
+				if err != nil {
+					return nil, err
+				}
+				var status string
+				if err := json.Encode(w.BuildStatus(), &status); err != nil {
+					return nil, err
+				}
+				return textResult(string(data), result.Summary(), status)
+			},
+		},
+		{
+			Name: "wasm_set_storage",
+			Description: "Choose where the WebAssembly binary is kept: memory (compiled into memory and served from there, " +
+				"the development default) or disk (written to " + w.OutputRelativePath() + " for a static server or a deploy). " +
+				"Switching recompiles the current mode so the new storage holds the binary, and the registered HTTP route serves from it at once.",
+			Args:     new(SetStorageArgs),
+			Resource: "wasm",
+			Action:   'u',
+			Execute: func(ctx *context.Context, req mcp.Request) (*mcp.Result, error) {
+				var args SetStorageArgs
+				if err := req.Bind(&args); err != nil {
+					return nil, err
+				}
+				changed, err := w.SetStorage(args.Storage)
+				if !changed && err != nil {
+					return nil, err
+				}
+				if err != nil {
+					return nil, Errf("storage changed to %s but the build failed (see wasm_build_status): %w", args.Storage, err)
+				}
+				layout := w.Layout()
+				if !changed {
+					return mcp.Text("Storage already " + args.Storage + "\n" + layout.Summary()), nil
+				}
+				return mcp.Text("Storage changed to " + args.Storage + "\n" + layout.Summary()), nil
+			},
+		},
+	}
+}
+
+// clientTemplatesLabel lists the templates with their description for a tool
+// description; a user template that fails to load is named instead.
+func (w *WasmClient) clientTemplatesLabel() string {
+	templates, err := w.Templates()
+	labels := make([]string, 0, len(templates)+1)
+	for _, t := range templates {
+		if t.Description == "" {
+			labels = append(labels, t.Name)
+			continue
+		}
+		labels = append(labels, t.Name+" ("+t.Description+")")
+	}
+	if err != nil {
+		labels = append(labels, "user templates not loaded: "+err.Error())
+	}
+	return strings.Join(labels, ", ")
+}
+
+// textResult returns texts as the text contents of a tool result.
+func textResult(texts ...string) (*mcp.Result, error) {
+	list := make(mcp.TextContentList, 0, len(texts))
+	for _, t := range texts {
+		list = append(list, &mcp.TextContent{Type: "text", Text: t})
+	}
+	var out string
+	if err := json.Encode(&list, &out); err != nil {
+		return nil, err
+	}
+	return &mcp.Result{Content: out}, nil
+}
+
+// buildStatusResult returns status as the JSON text of a tool result.
+func buildStatusResult(status *BuildStatus) (*mcp.Result, error) {
+	var out string
+	if err := json.Encode(status, &out); err != nil {
+		return nil, err
+	}
+	return mcp.Text(out), nil
+}
+
+// jsonSummaryResult returns v as compact JSON followed by a human summary,
+// as two text contents of a tool result.
+func jsonSummaryResult(v any, summary string) (*mcp.Result, error) {
+	data, err := stdjson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(string(data), summary)
+}
+
+// modeSizeLabel describes the last known binary size of mode for a tool description.
+func (w *WasmClient) modeSizeLabel(mode string) string {
+	if size := w.ModeSize(mode); size > 0 {
+		return "last build " + formatBytes(size)
+	}
+	return "not built yet"
+}
+
+// modeArgsFielder is an ormc-generated tool argument model.
+type modeArgsFielder interface {
+	model.Fielder
+	mcp.DecodableFields
+}
+
+// shortcutArgs is a tool argument model whose "mode" field permits the
+// configured shortcuts and custom profiles instead of the L/M/S of its
+// generated Schema.
+type shortcutArgs struct {
+	modeArgsFielder
+	fields []model.Field
+}
+
+func (a *shortcutArgs) Schema() []model.Field { return a.fields }
+
+func (a *shortcutArgs) Validate(action byte) error { return model.ValidateFields(action, a) }
+
+// modeArgs wraps args so its schema and validation follow the shortcuts and
+// the custom profiles of tinywasm.json.
+func (w *WasmClient) modeArgs(args modeArgsFielder) *shortcutArgs {
+	shortcuts := append([]string{w.buildLargeSizeShortcut, w.buildMediumSizeShortcut, w.buildSmallSizeShortcut}, w.profileNames()...)
+	fields := append([]model.Field{}, args.Schema()...)
+	for i, f := range fields {
+		if f.Name != "mode" {
+			continue
+		}
+		f.Permitted.Extra, f.Permitted.Maximum = nil, 0
+		for _, s := range shortcuts {
+			for _, r := range s {
+				if !containsRune(f.Permitted.Extra, r) {
+					f.Permitted.Extra = append(f.Permitted.Extra, r)
+				}
+			}
+			if n := len([]rune(s)); n > f.Permitted.Maximum {
+				f.Permitted.Maximum = n
+			}
+		}
+		fields[i] = f
+	}
+	return &shortcutArgs{modeArgsFielder: args, fields: fields}
+}
+
+func containsRune(list []rune, r rune) bool {
+	for _, v := range list {
+		if v == r {
+			return true
+		}
 	}
+	return false
 }
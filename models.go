@@ -7,7 +7,11 @@ import "github.com/tinywasm/model"
 // this literal; the MCP inputSchema is derived from the generated Schema().
 // mode is exactly one of L/M/S — expressed as: length exactly 1, allowed
 // characters only 'L','M','S' (Permitted has no enum concept; this is the
-// faithful typed equivalent for single-letter modes).
+// faithful typed equivalent for single-letter modes). GetMCPTools swaps those
+// characters for the configured shortcuts (see modeArgs in mcp-tool.go).
+// on_findings is the optional pre-flight decision (proceed/abort/large) used
+// when switching to M/S would compile unsupported packages; it is checked by
+// ParsePreflightDecision.
 var SetModeArgsModel = model.Definition{
 	Name: "set_mode_args",
 	Fields: model.Fields{
@@ -21,5 +25,121 @@ var SetModeArgsModel = model.Definition{
 				Maximum: 1,
 			},
 		},
+		{
+			Name: "on_findings",
+			Type: model.Text(),
+			Permitted: model.Permitted{
+				Letters: true,
+				Maximum: 7,
+			},
+		},
+	},
+}
+
+// BuildDiagnosticModel is one compiler error of the last build, as returned
+// inside BuildStatusModel.
+var BuildDiagnosticModel = model.Definition{
+	Name: "build_diagnostic",
+	Fields: model.Fields{
+		{Name: "file", Type: model.Text(), OmitEmpty: true},
+		{Name: "line", Type: model.Int(), OmitEmpty: true},
+		{Name: "column", Type: model.Int(), OmitEmpty: true},
+		{Name: "message", Type: model.Text()},
+	},
+}
+
+// BuildStatusModel defines the result of the read-only wasm_build_status MCP
+// tool: the current mode and storage plus the outcome of the last build.
+// state is "ok", "failed" or "none" (nothing compiled yet); built_at is
+// RFC 3339 and size is the binary size in bytes, 0 when unknown.
+var BuildStatusModel = model.Definition{
+	Name: "build_status",
+	Fields: model.Fields{
+		{Name: "mode", Type: model.Text()},
+		{Name: "compiler", Type: model.Text()},
+		{Name: "storage", Type: model.Text()},
+		{Name: "state", Type: model.Text()},
+		{Name: "error", Type: model.Text(), OmitEmpty: true},
+		{Name: "diagnostics", Type: model.StructSlice(&BuildDiagnosticModel), OmitEmpty: true},
+		{Name: "size", Type: model.Int(), OmitEmpty: true},
+		{Name: "built_at", Type: model.Text(), OmitEmpty: true},
+		{Name: "duration_ms", Type: model.Int(), OmitEmpty: true},
+	},
+}
+
+// CompileArgsModel defines the arguments of the wasm_compile MCP tool. mode
+// is optional (empty = current mode) with the same shortcut rule as
+// SetModeArgsModel; timeout_seconds bounds the wait, 0 = DefaultCompileTimeout.
+var CompileArgsModel = model.Definition{
+	Name: "compile_args",
+	Fields: model.Fields{
+		{
+			Name: "mode",
+			Type: model.Text(),
+			Permitted: model.Permitted{
+				Extra:   []rune{'L', 'M', 'S'},
+				Maximum: 1,
+			},
+		},
+		{Name: "timeout_seconds", Type: model.Int()},
+	},
+}
+
+// SizeReportArgsModel defines the arguments of the wasm_size_report MCP tool:
+// the optional mode to inspect (empty = the served binary of the current mode).
+var SizeReportArgsModel = model.Definition{
+	Name: "size_report_args",
+	Fields: model.Fields{
+		{
+			Name: "mode",
+			Type: model.Text(),
+			Permitted: model.Permitted{
+				Extra:   []rune{'L', 'M', 'S'},
+				Maximum: 1,
+			},
+		},
+	},
+}
+
+// GenerateClientArgsModel defines the arguments of the wasm_generate_client
+// MCP tool: the optional template name (empty = DefaultClientTemplate, checked
+// by Scaffold), whether to skip the IDE wasm settings and the optional
+// app_name and mount_id template variables (empty = DefaultTemplateVars,
+// checked by Scaffold) and dry_run to return the PlanScaffold plan instead.
+var GenerateClientArgsModel = model.Definition{
+	Name: "generate_client_args",
+	Fields: model.Fields{
+		{
+			Name: "template",
+			Type: model.Text(),
+			Permitted: model.Permitted{
+				Letters: true,
+				Numbers: true,
+				Extra:   []rune{'-', '_'},
+				Maximum: 40,
+			},
+		},
+		{Name: "skip_ide_config", Type: model.Bool()},
+		{Name: "app_name", Type: model.Text(), Permitted: model.Permitted{Maximum: 80}},
+		{Name: "mount_id", Type: model.Text(), Permitted: model.Permitted{Maximum: 80}},
+		{Name: "dry_run", Type: model.Bool()},
+	},
+}
+
+// SetStorageArgsModel defines the arguments of the wasm_set_storage MCP tool:
+// storage is StorageMemory or StorageDisk, checked by SetStorage.
+var SetStorageArgsModel = model.Definition{
+	Name: "set_storage_args",
+	Fields: model.Fields{
+		{
+			Name:    "storage",
+			Type:    model.Text(),
+			NotNull: true,
+			Permitted: model.Permitted{
+				Letters: true,
+				Minimum: 4,
+				Maximum: 6,
+			},
+		},
 	},
 }
@@ -7,23 +7,26 @@ import (
 )
 
 type SetModeArgs struct {
-	Mode string
+	Mode       string
+	OnFindings string
 }
 
 func (m *SetModeArgs) ModelName() string { return "set_mode_args" }
 
 func (m *SetModeArgs) Schema() []model.Field { return SetModeArgsModel.Fields }
 
-func (m *SetModeArgs) Pointers() []any { return []any{&m.Mode} }
+func (m *SetModeArgs) Pointers() []any { return []any{&m.Mode, &m.OnFindings} }
 
 func (m *SetModeArgs) IsNil() bool { return m == nil }
 
 func (m *SetModeArgs) EncodeFields(w model.FieldWriter) {
 	w.String("mode", m.Mode)
+	w.String("on_findings", m.OnFindings)
 }
 
 func (m *SetModeArgs) DecodeFields(r model.FieldReader) {
 	if v, ok := r.String("mode"); ok { m.Mode = v }
+	if v, ok := r.String("on_findings"); ok { m.OnFindings = v }
 }
 
 type SetModeArgsList []*SetModeArgs
@@ -41,3 +44,269 @@ func (m *SetModeArgs) Validate(action byte) error {
 	return model.ValidateFields(action, m)
 }
 
+
+type CompileArgs struct {
+	Mode           string
+	TimeoutSeconds int64
+}
+
+func (m *CompileArgs) ModelName() string { return "compile_args" }
+
+func (m *CompileArgs) Schema() []model.Field { return CompileArgsModel.Fields }
+
+func (m *CompileArgs) Pointers() []any { return []any{&m.Mode, &m.TimeoutSeconds} }
+
+func (m *CompileArgs) IsNil() bool { return m == nil }
+
+func (m *CompileArgs) EncodeFields(w model.FieldWriter) {
+	w.String("mode", m.Mode)
+	w.Int("timeout_seconds", m.TimeoutSeconds)
+}
+
+func (m *CompileArgs) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("mode"); ok { m.Mode = v }
+	if v, ok := r.Int("timeout_seconds"); ok { m.TimeoutSeconds = v }
+}
+
+type CompileArgsList []*CompileArgs
+
+func (s *CompileArgsList) Schema() []model.Field { return nil }
+func (s *CompileArgsList) Pointers() []any     { return nil }
+func (s *CompileArgsList) Len() int             { return len(*s) }
+func (s *CompileArgsList) At(i int) model.Fielder { return (*s)[i] }
+func (s *CompileArgsList) Append() model.Fielder  { v := &CompileArgs{}; *s = append(*s, v); return v }
+func (s *CompileArgsList) IsNil() bool          { return s == nil }
+func (s *CompileArgsList) EncodeFields(_ model.FieldWriter) {}
+func (s *CompileArgsList) DecodeFields(_ model.FieldReader) {}
+
+func (m *CompileArgs) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
+
+type SizeReportArgs struct {
+	Mode string
+}
+
+func (m *SizeReportArgs) ModelName() string { return "size_report_args" }
+
+func (m *SizeReportArgs) Schema() []model.Field { return SizeReportArgsModel.Fields }
+
+func (m *SizeReportArgs) Pointers() []any { return []any{&m.Mode} }
+
+func (m *SizeReportArgs) IsNil() bool { return m == nil }
+
+func (m *SizeReportArgs) EncodeFields(w model.FieldWriter) {
+	w.String("mode", m.Mode)
+}
+
+func (m *SizeReportArgs) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("mode"); ok { m.Mode = v }
+}
+
+type SizeReportArgsList []*SizeReportArgs
+
+func (s *SizeReportArgsList) Schema() []model.Field { return nil }
+func (s *SizeReportArgsList) Pointers() []any     { return nil }
+func (s *SizeReportArgsList) Len() int             { return len(*s) }
+func (s *SizeReportArgsList) At(i int) model.Fielder { return (*s)[i] }
+func (s *SizeReportArgsList) Append() model.Fielder  { v := &SizeReportArgs{}; *s = append(*s, v); return v }
+func (s *SizeReportArgsList) IsNil() bool          { return s == nil }
+func (s *SizeReportArgsList) EncodeFields(_ model.FieldWriter) {}
+func (s *SizeReportArgsList) DecodeFields(_ model.FieldReader) {}
+
+func (m *SizeReportArgs) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
+
+type BuildDiagnostic struct {
+	File    string
+	Line    int64
+	Column  int64
+	Message string
+}
+
+func (m *BuildDiagnostic) ModelName() string { return "build_diagnostic" }
+
+func (m *BuildDiagnostic) Schema() []model.Field { return BuildDiagnosticModel.Fields }
+
+func (m *BuildDiagnostic) Pointers() []any { return []any{&m.File, &m.Line, &m.Column, &m.Message} }
+
+func (m *BuildDiagnostic) IsNil() bool { return m == nil }
+
+func (m *BuildDiagnostic) EncodeFields(w model.FieldWriter) {
+	if m.File != "" { w.String("file", m.File) }
+	if m.Line != 0 { w.Int("line", m.Line) }
+	if m.Column != 0 { w.Int("column", m.Column) }
+	w.String("message", m.Message)
+}
+
+func (m *BuildDiagnostic) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("file"); ok { m.File = v }
+	if v, ok := r.Int("line"); ok { m.Line = v }
+	if v, ok := r.Int("column"); ok { m.Column = v }
+	if v, ok := r.String("message"); ok { m.Message = v }
+}
+
+type BuildDiagnosticList []*BuildDiagnostic
+
+func (s *BuildDiagnosticList) Schema() []model.Field { return nil }
+func (s *BuildDiagnosticList) Pointers() []any     { return nil }
+func (s *BuildDiagnosticList) Len() int             { return len(*s) }
+func (s *BuildDiagnosticList) At(i int) model.Fielder { return (*s)[i] }
+func (s *BuildDiagnosticList) Append() model.Fielder  { v := &BuildDiagnostic{}; *s = append(*s, v); return v }
+func (s *BuildDiagnosticList) IsNil() bool          { return s == nil }
+func (s *BuildDiagnosticList) EncodeFields(_ model.FieldWriter) {}
+func (s *BuildDiagnosticList) DecodeFields(_ model.FieldReader) {}
+
+func (m *BuildDiagnostic) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
+
+type BuildStatus struct {
+	Mode        string
+	Compiler    string
+	Storage     string
+	State       string
+	Error       string
+	Diagnostics BuildDiagnosticList
+	Size        int64
+	BuiltAt     string
+	DurationMs  int64
+}
+
+func (m *BuildStatus) ModelName() string { return "build_status" }
+
+func (m *BuildStatus) Schema() []model.Field { return BuildStatusModel.Fields }
+
+func (m *BuildStatus) Pointers() []any {
+	return []any{&m.Mode, &m.Compiler, &m.Storage, &m.State, &m.Error, &m.Diagnostics, &m.Size, &m.BuiltAt, &m.DurationMs}
+}
+
+func (m *BuildStatus) IsNil() bool { return m == nil }
+
+func (m *BuildStatus) EncodeFields(w model.FieldWriter) {
+	w.String("mode", m.Mode)
+	w.String("compiler", m.Compiler)
+	w.String("storage", m.Storage)
+	w.String("state", m.State)
+	if m.Error != "" { w.String("error", m.Error) }
+	if len(m.Diagnostics) > 0 {
+		aw := w.Array("diagnostics", len(m.Diagnostics))
+		for _, v := range m.Diagnostics { aw.Object(v) }
+		aw.Close()
+	}
+	if m.Size != 0 { w.Int("size", m.Size) }
+	if m.BuiltAt != "" { w.String("built_at", m.BuiltAt) }
+	if m.DurationMs != 0 { w.Int("duration_ms", m.DurationMs) }
+}
+
+func (m *BuildStatus) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("mode"); ok { m.Mode = v }
+	if v, ok := r.String("compiler"); ok { m.Compiler = v }
+	if v, ok := r.String("storage"); ok { m.Storage = v }
+	if v, ok := r.String("state"); ok { m.State = v }
+	if v, ok := r.String("error"); ok { m.Error = v }
+	if ar, ok := r.Array("diagnostics"); ok {
+		for i := 0; i < ar.Len(); i++ { ar.Object(i, m.Diagnostics.Append().(*BuildDiagnostic)) }
+	}
+	if v, ok := r.Int("size"); ok { m.Size = v }
+	if v, ok := r.String("built_at"); ok { m.BuiltAt = v }
+	if v, ok := r.Int("duration_ms"); ok { m.DurationMs = v }
+}
+
+type BuildStatusList []*BuildStatus
+
+func (s *BuildStatusList) Schema() []model.Field { return nil }
+func (s *BuildStatusList) Pointers() []any     { return nil }
+func (s *BuildStatusList) Len() int             { return len(*s) }
+func (s *BuildStatusList) At(i int) model.Fielder { return (*s)[i] }
+func (s *BuildStatusList) Append() model.Fielder  { v := &BuildStatus{}; *s = append(*s, v); return v }
+func (s *BuildStatusList) IsNil() bool          { return s == nil }
+func (s *BuildStatusList) EncodeFields(_ model.FieldWriter) {}
+func (s *BuildStatusList) DecodeFields(_ model.FieldReader) {}
+
+func (m *BuildStatus) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
+
+type GenerateClientArgs struct {
+	Template      string
+	SkipIdeConfig bool
+	AppName       string
+	MountId       string
+	DryRun        bool
+}
+
+func (m *GenerateClientArgs) ModelName() string { return "generate_client_args" }
+
+func (m *GenerateClientArgs) Schema() []model.Field { return GenerateClientArgsModel.Fields }
+
+func (m *GenerateClientArgs) Pointers() []any { return []any{&m.Template, &m.SkipIdeConfig, &m.AppName, &m.MountId, &m.DryRun} }
+
+func (m *GenerateClientArgs) IsNil() bool { return m == nil }
+
+func (m *GenerateClientArgs) EncodeFields(w model.FieldWriter) {
+	w.String("template", m.Template)
+	w.Bool("skip_ide_config", m.SkipIdeConfig)
+	w.String("app_name", m.AppName)
+	w.String("mount_id", m.MountId)
+	w.Bool("dry_run", m.DryRun)
+}
+
+func (m *GenerateClientArgs) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("template"); ok { m.Template = v }
+	if v, ok := r.Bool("skip_ide_config"); ok { m.SkipIdeConfig = v }
+	if v, ok := r.String("app_name"); ok { m.AppName = v }
+	if v, ok := r.String("mount_id"); ok { m.MountId = v }
+	if v, ok := r.Bool("dry_run"); ok { m.DryRun = v }
+}
+
+type GenerateClientArgsList []*GenerateClientArgs
+
+func (s *GenerateClientArgsList) Schema() []model.Field { return nil }
+func (s *GenerateClientArgsList) Pointers() []any     { return nil }
+func (s *GenerateClientArgsList) Len() int             { return len(*s) }
+func (s *GenerateClientArgsList) At(i int) model.Fielder { return (*s)[i] }
+func (s *GenerateClientArgsList) Append() model.Fielder  { v := &GenerateClientArgs{}; *s = append(*s, v); return v }
+func (s *GenerateClientArgsList) IsNil() bool          { return s == nil }
+func (s *GenerateClientArgsList) EncodeFields(_ model.FieldWriter) {}
+func (s *GenerateClientArgsList) DecodeFields(_ model.FieldReader) {}
+
+func (m *GenerateClientArgs) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
+
+type SetStorageArgs struct {
+	Storage string
+}
+
+func (m *SetStorageArgs) ModelName() string { return "set_storage_args" }
+
+func (m *SetStorageArgs) Schema() []model.Field { return SetStorageArgsModel.Fields }
+
+func (m *SetStorageArgs) Pointers() []any { return []any{&m.Storage} }
+
+func (m *SetStorageArgs) IsNil() bool { return m == nil }
+
+func (m *SetStorageArgs) EncodeFields(w model.FieldWriter) {
+	w.String("storage", m.Storage)
+}
+
+func (m *SetStorageArgs) DecodeFields(r model.FieldReader) {
+	if v, ok := r.String("storage"); ok { m.Storage = v }
+}
+
+type SetStorageArgsList []*SetStorageArgs
+
+func (s *SetStorageArgsList) Schema() []model.Field { return nil }
+func (s *SetStorageArgsList) Pointers() []any     { return nil }
+func (s *SetStorageArgsList) Len() int             { return len(*s) }
+func (s *SetStorageArgsList) At(i int) model.Fielder { return (*s)[i] }
+func (s *SetStorageArgsList) Append() model.Fielder  { v := &SetStorageArgs{}; *s = append(*s, v); return v }
+func (s *SetStorageArgsList) IsNil() bool          { return s == nil }
+func (s *SetStorageArgsList) EncodeFields(_ model.FieldWriter) {}
+func (s *SetStorageArgsList) DecodeFields(_ model.FieldReader) {}
+
+func (m *SetStorageArgs) Validate(action byte) error {
+	return model.ValidateFields(action, m)
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// tinyGoWasmTags are the build tags TinyGo sets for the wasm target on top of
+// the user tags; go list uses them to pick the same files TinyGo would.
+var tinyGoWasmTags = []string{"tinygo", "purego", "osusergo", "math_big_pure_go"}
+
+// wasmPackage is one package of the wasm entry's import graph as reported by go list.
+type wasmPackage struct {
+	ImportPath     string
+	Name           string
+	Dir            string
+	Standard       bool
+	DepOnly        bool
+	GoFiles        []string
+	IgnoredGoFiles []string
+	Imports        []string
+	Module         *struct {
+		Path string
+		Main bool
+	}
+	Error *struct {
+		Err string
+	}
+}
+
+// packageGraph is the transitive import graph of the wasm entry for one mode.
+type packageGraph struct {
+	Mode     string
+	Tags     []string
+	Entry    string                  // import path of the entry package (go list reports "command-line-arguments" for a file)
+	Packages map[string]*wasmPackage // by import path
+	Order    []string                // go list order: dependencies before dependents
+}
+
+// buildTags returns the build tags a compilation in mode uses: the "dev" tag
+// L mode adds or the TinyGo wasm tags, plus any -tags from CompilingArguments.
+func (w *WasmClient) buildTags(mode string) []string {
+	var tags []string
+	if w.RequiresTinyGo(mode) {
+		tags = append(tags, tinyGoWasmTags...)
+	} else {
+		tags = append(tags, "dev")
+	}
+
+	if w.CompilingArguments == nil {
+		return tags
+	}
+	args := w.CompilingArguments()
+	for i := 0; i < len(args); i++ {
+		var value string
+		switch {
+		case args[i] == "-tags" && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "-tags="):
+			value = strings.TrimPrefix(args[i], "-tags=")
+		default:
+			continue
+		}
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// loadPackageGraph lists the transitive imports of the main input file with
+// GOOS=js GOARCH=wasm and the build tags of mode, exactly as the compiler sees them.
+func (w *WasmClient) loadPackageGraph(mode string) (*packageGraph, error) {
+	tags := w.buildTags(mode)
+	mainFile := filepath.Join(w.Config.SourceDir(), w.MainInputFile)
+
+	cmd := exec.Command("go", "list", "-e", "-deps",
+		"-json=ImportPath,Name,Dir,Standard,DepOnly,GoFiles,IgnoredGoFiles,Imports,Module,Error",
+		"-tags", strings.Join(tags, ","), mainFile)
+	cmd.Dir = w.AppRootDir
+	cmd.Env = append(os.Environ(), w.Config.Env...)
+	cmd.Env = append(cmd.Env, "GOOS=js", "GOARCH=wasm")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, Errf("go list %s failed: %v %s", mainFile, err, strings.TrimSpace(stderr.String()))
+	}
+
+	g := &packageGraph{Mode: mode, Tags: tags, Packages: map[string]*wasmPackage{}}
+	dec := json.NewDecoder(&stdout)
+	for {
+		var p wasmPackage
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, Errf("decoding go list output: %v", err)
+		}
+		g.Packages[p.ImportPath] = &p
+		g.Order = append(g.Order, p.ImportPath)
+		if !p.DepOnly {
+			g.Entry = p.ImportPath
+		}
+	}
+
+	entry := g.Packages[g.Entry]
+	if entry == nil {
+		return nil, Errf("go list %s: entry package not found", mainFile)
+	}
+	if entry.Error != nil {
+		return nil, Errf("loading %s: %s", mainFile, entry.Error.Err)
+	}
+	return g, nil
+}
+
+// walk visits the graph breadth-first from the entry and returns, for every
+// reached package, the import chain that leads to it (entry first). Packages
+// for which stop returns true are reached but their imports are not followed.
+func (g *packageGraph) walk(stop func(path string) bool) map[string][]string {
+	chains := map[string][]string{g.Entry: {g.Entry}}
+	queue := []string{g.Entry}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current != g.Entry && stop != nil && stop(current) {
+			continue
+		}
+		p := g.Packages[current]
+		if p == nil {
+			continue
+		}
+		for _, imp := range p.Imports {
+			if _, seen := chains[imp]; seen {
+				continue
+			}
+			chain := make([]string, len(chains[current]), len(chains[current])+1)
+			copy(chain, chains[current])
+			chains[imp] = append(chain, imp)
+			queue = append(queue, imp)
+		}
+	}
+	return chains
+}
+
+// chain returns the shortest import chain from the entry to path, or nil.
+func (g *packageGraph) chain(path string) []string {
+	return g.walk(nil)[path]
+}
+
+// relPath returns path relative to AppRootDir with forward slashes, or path
+// itself when it lies outside the project (module cache, GOROOT).
+func (w *WasmClient) relPath(path string) string {
+	root, err := filepath.Abs(w.AppRootDir)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// PreflightDecision is the caller's answer to a TinyGo pre-flight that found
+// unsupported packages in the wasm entry's import graph.
+type PreflightDecision string
+
+const (
+	PreflightProceed PreflightDecision = "proceed" // switch to the TinyGo mode anyway
+	PreflightAbort   PreflightDecision = "abort"   // keep the current mode
+	PreflightLarge   PreflightDecision = "large"   // switch to (or stay in) the Go stdlib mode
+)
+
+// ParsePreflightDecision validates a decision received as text (MCP argument, CLI flag).
+// "" maps to PreflightAbort.
+func ParsePreflightDecision(s string) (PreflightDecision, error) {
+	switch d := PreflightDecision(Convert(s).ToLower().String()); d {
+	case "":
+		return PreflightAbort, nil
+	case PreflightProceed, PreflightAbort, PreflightLarge:
+		return d, nil
+	}
+	return "", Err("on_findings", ":", s, "invalid", "valid", ":", []string{string(PreflightProceed), string(PreflightAbort), string(PreflightLarge)})
+}
+
+// SetOnTinyGoPreflight sets the callback asked what to do when switching to a
+// TinyGo mode would compile unsupported packages. Without it, Change aborts the
+// first time and proceeds when the same mode is selected again.
+func (w *WasmClient) SetOnTinyGoPreflight(f func(mode string, report *TinyGoReport) PreflightDecision) {
+	w.OnTinyGoPreflight = f
+}
+
+// preflightTinyGo runs the compatibility analyzer before switching from mode
+// current to the TinyGo mode target and returns the mode to switch to ("" =
+// abort) together with the report (nil when no analysis ran). Discouraged
+// packages are only logged; decide is asked only for unsupported ones.
+// An analyzer failure never blocks the switch.
+func (w *WasmClient) preflightTinyGo(current, target string, decide func(string, *TinyGoReport) PreflightDecision) (string, *TinyGoReport) {
+	if !w.RequiresTinyGo(target) || current == target {
+		return target, nil
+	}
+
+	report, err := w.AnalyzeTinyGoCompatibility()
+	if err != nil {
+		w.Logger("TinyGo pre-flight skipped:", err)
+		return target, nil
+	}
+	if len(report.Findings) > 0 {
+		w.Logger(report.Summary())
+	}
+	if report.Compatible() {
+		return target, report
+	}
+
+	switch decide(target, report) {
+	case PreflightProceed:
+		return target, report
+	case PreflightLarge:
+		return w.buildLargeSizeShortcut, report
+	default:
+		return "", report
+	}
+}
+
+// defaultPreflightDecision is the decision used by Change: the OnTinyGoPreflight
+// callback when set, otherwise a TUI confirmation where selecting the same
+// mode a second time proceeds.
+func (w *WasmClient) defaultPreflightDecision(mode string, report *TinyGoReport) PreflightDecision {
+	if w.OnTinyGoPreflight != nil {
+		return w.OnTinyGoPreflight(mode, report)
+	}
+
+	w.storageMu.Lock()
+	confirmed := w.preflightPending == mode
+	w.preflightPending = mode
+	w.storageMu.Unlock()
+
+	if confirmed {
+		return PreflightProceed
+	}
+	w.Logger("Select " + mode + " again to compile with TinyGo anyway, or " + w.buildLargeSizeShortcut + " to stay on the Go stdlib")
+	return PreflightAbort
+}
@@ -0,0 +1,40 @@
+package client
+
+// Stages of a BuildProgress.
+const (
+	ProgressPreflight = "preflight" // TinyGo compatibility analysis before switching to M/S
+	ProgressInstall   = "install"   // TinyGo is being downloaded and installed
+	ProgressCompile   = "compile"   // compilation started
+	ProgressDone      = "done"      // compilation finished
+	ProgressFailed    = "failed"    // the install or the compilation failed
+)
+
+// BuildProgress is one step of a mode change or a compilation.
+type BuildProgress struct {
+	Stage   string `json:"stage"` // one of the Progress* constants
+	Mode    string `json:"mode"`
+	Message string `json:"message"`
+}
+
+// SetOnProgress registers fn to receive the steps of mode changes and
+// compilations (file events, RecompileMainWasm, CompileAndWait) as they happen.
+// fn runs on the goroutine doing the work.
+func (w *WasmClient) SetOnProgress(fn func(BuildProgress)) {
+	w.OnProgress = fn
+}
+
+// reportProgress sends a step to OnProgress.
+func (w *WasmClient) reportProgress(stage, mode, message string) {
+	if w.OnProgress != nil {
+		w.OnProgress(BuildProgress{Stage: stage, Mode: mode, Message: message})
+	}
+}
+
+// reportCompiled sends the outcome of a compilation of mode to OnProgress.
+func (w *WasmClient) reportCompiled(mode string, err error) {
+	if err != nil {
+		w.reportProgress(ProgressFailed, mode, "compilation failed: "+err.Error())
+		return
+	}
+	w.reportProgress(ProgressDone, mode, "compiled mode "+mode)
+}
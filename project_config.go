@@ -0,0 +1,462 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// ProjectConfigFile is the project file read from AppRootDir by New,
+// SetAppRootDir and the wasmbuild commands, so the app and the CLI share one
+// declarative configuration.
+const ProjectConfigFile = "tinywasm.json"
+
+// ProjectConfig is the content of tinywasm.json. Every field is optional: an
+// empty value keeps the default (or the value set in code / by a flag).
+//
+//	{
+//	  "source_dir": "web",
+//	  "main_file": "client.go",
+//	  "output_dir": "web/public",
+//	  "output_name": "client",
+//	  "assets_url_prefix": "assets",
+//	  "mode": "prod",
+//	  "compiling_arguments": ["-ldflags", "-X main.version=1.2.0"],
+//	  "env": ["GOFLAGS=-mod=vendor"],
+//	  "shortcuts": {"large": "L", "medium": "M", "small": "S"},
+//	  "max_size": "500KB",
+//	  "reproducible": true,
+//	  "toolchain": "go1.25.2",
+//	  "template_dir": "templates",
+//	  "profiles": {
+//	    "staging": {"mode": "S", "compiling_arguments": ["-ldflags", "-X main.env=staging"], "env": ["API_URL=https://staging.example.com"]}
+//	  }
+//	}
+type ProjectConfig struct {
+	SourceDir          string          `json:"source_dir"`          // directory of the main file, relative to AppRootDir
+	MainFile           string          `json:"main_file"`           // main input file inside source_dir
+	OutputDir          string          `json:"output_dir"`          // directory for the .wasm file, relative to AppRootDir
+	OutputName         string          `json:"output_name"`         // .wasm file name without extension
+	AssetsURLPrefix    string          `json:"assets_url_prefix"`   // URL folder the .wasm file is served under
+	Mode               string          `json:"mode"`                // initial mode: L, M, S, a built-in profile (large/dev, medium/debug, small/prod) or one of profiles
+	CompilingArguments []string        `json:"compiling_arguments"` // extra compiler arguments
+	Env                []string        `json:"env"`                 // extra compiler environment, KEY=VALUE
+	Shortcuts          ProjectShortcut `json:"shortcuts"`           // mode shortcuts shown in the TUI
+	MaxSize            string          `json:"max_size"`            // wasmbuild size budget, eg: "500KB"
+	Reproducible       bool            `json:"reproducible"`        // byte-for-byte repeatable builds (see Config.Reproducible)
+	Toolchain          string          `json:"toolchain"`           // GOTOOLCHAIN of reproducible builds, eg: "go1.25.2" or "local"
+	TemplateDir        string          `json:"template_dir"`        // directory of user client templates, relative to AppRootDir
+
+	Profiles map[string]ProjectProfile `json:"profiles"` // custom profiles selected by mode or -mode, by lowercase name
+}
+
+// ProjectProfile is a custom build profile: a size mode plus the compiler
+// arguments and environment added after the file's own.
+type ProjectProfile struct {
+	Mode               string   `json:"mode"`                // L, M, S or a built-in profile, required
+	CompilingArguments []string `json:"compiling_arguments"` // added after compiling_arguments
+	Env                []string `json:"env"`                 // added after env, KEY=VALUE
+}
+
+// profile returns the custom profile called name, case-insensitively.
+func (pc *ProjectConfig) profile(name string) (ProjectProfile, bool) {
+	p, ok := pc.Profiles[Convert(name).ToLower().String()]
+	return p, ok
+}
+
+// sizeMode resolves name (L, M, S, a built-in or a custom profile) to L, M or S.
+func (pc *ProjectConfig) sizeMode(name string) (string, error) {
+	if p, ok := pc.profile(name); ok {
+		name = p.Mode
+	}
+	return WasmBuildArgs{Mode: name}.mode()
+}
+
+// ProjectShortcut holds the shortcuts of the three compilation modes.
+type ProjectShortcut struct {
+	Large  string `json:"large"`
+	Medium string `json:"medium"`
+	Small  string `json:"small"`
+}
+
+// LoadProjectConfig reads and validates tinywasm.json in rootDir. A missing
+// file returns nil, nil. Errors name the file and, for syntax errors, the
+// line and column.
+func LoadProjectConfig(rootDir string) (*ProjectConfig, error) {
+	path := filepath.Join(rootDir, ProjectConfigFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &ProjectConfig{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(pc); err != nil {
+		return nil, Errf("%s: %s", projectConfigPosition(path, data, err), projectConfigDecodeError(err))
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, Errf("%s: unexpected content after the JSON object", path)
+	}
+	if err := pc.Validate(); err != nil {
+		return nil, Errf("%s: %w", path, err)
+	}
+	return pc, nil
+}
+
+// projectConfigPosition appends line:column to path for errors that carry an offset.
+func projectConfigPosition(path string, data []byte, err error) string {
+	var offset int64 = -1
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		offset = syntax.Offset
+	case errors.As(err, &typ):
+		offset = typ.Offset
+	}
+	if offset <= 0 || offset > int64(len(data)) {
+		return path
+	}
+	// offset counts the bytes read, the last one being the offending byte
+	before := data[:offset-1]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - 1 - bytes.LastIndexByte(before, '\n')
+	return path + ":" + strconv.Itoa(line) + ":" + strconv.Itoa(column)
+}
+
+// projectConfigDecodeError rewords the json package errors users hit most.
+func projectConfigDecodeError(err error) string {
+	var typ *json.UnmarshalTypeError
+	if errors.As(err, &typ) {
+		return Sprintf("%s must be a %s, not a %s", typ.Field, typ.Type.String(), typ.Value)
+	}
+	msg := strings.TrimPrefix(err.Error(), "json: ")
+	if field, ok := strings.CutPrefix(msg, "unknown field "); ok {
+		return "unknown field " + field + " (see client.ProjectConfig for the accepted fields)"
+	}
+	return msg
+}
+
+// Validate checks the field values and reports every problem found, one per line.
+func (pc *ProjectConfig) Validate() error {
+	var problems []string
+	add := func(field, value, msg string) {
+		problems = append(problems, field+" "+strconv.Quote(value)+": "+msg)
+	}
+
+	for _, d := range []struct{ field, dir string }{
+		{"source_dir", pc.SourceDir},
+		{"output_dir", pc.OutputDir},
+		{"template_dir", pc.TemplateDir},
+	} {
+		if d.dir != "" && (filepath.IsAbs(d.dir) || strings.HasPrefix(filepath.ToSlash(filepath.Clean(d.dir)), "../")) {
+			add(d.field, d.dir, "must be a path inside the project root")
+		}
+	}
+	if pc.MainFile != "" && (filepath.Ext(pc.MainFile) != ".go" || strings.ContainsAny(pc.MainFile, `/\`)) {
+		add("main_file", pc.MainFile, "must be a .go file name inside source_dir")
+	}
+	if pc.OutputName != "" && (strings.ContainsAny(pc.OutputName, `/\`) || filepath.Ext(pc.OutputName) != "") {
+		add("output_name", pc.OutputName, "must be a file name without directory or extension, eg: client")
+	}
+	if pc.Mode != "" {
+		if _, err := pc.sizeMode(pc.Mode); err != nil {
+			add("mode", pc.Mode, "use L, M, S, one of large, dev, medium, debug, small, prod or a name of profiles")
+		}
+	}
+	checkEnv := func(field string, env []string) {
+		for i, kv := range env {
+			if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+				add(field+"["+strconv.Itoa(i)+"]", kv, "must be KEY=VALUE")
+			}
+		}
+	}
+	checkEnv("env", pc.Env)
+	seen := map[string]string{}
+	for _, s := range []struct{ field, value string }{
+		{"shortcuts.large", pc.Shortcuts.Large},
+		{"shortcuts.medium", pc.Shortcuts.Medium},
+		{"shortcuts.small", pc.Shortcuts.Small},
+	} {
+		if s.value == "" {
+			continue
+		}
+		if len([]rune(s.value)) != 1 {
+			add(s.field, s.value, "must be a single character")
+		} else if other, dup := seen[s.value]; dup {
+			add(s.field, s.value, "already used by "+other)
+		}
+		seen[s.value] = s.field
+	}
+	if pc.MaxSize != "" {
+		if _, err := ParseByteSize(pc.MaxSize); err != nil {
+			add("max_size", pc.MaxSize, "use bytes or a KB/MB suffix, eg: 500KB")
+		}
+	}
+	if pc.Toolchain != "" && pc.Toolchain != "local" && (!strings.HasPrefix(pc.Toolchain, "go1.") || versionMinor(strings.TrimPrefix(pc.Toolchain, "go")) < 0) {
+		add("toolchain", pc.Toolchain, "use local or a Go release, eg: go1.25.2")
+	}
+	names := make([]string, 0, len(pc.Profiles))
+	for name := range pc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p, field := pc.Profiles[name], "profiles."+name
+		if _, err := (WasmBuildArgs{Mode: name}).mode(); err == nil {
+			add(field, name, "already a built-in mode, choose another name")
+		} else if name == "" || Convert(name).ToLower().String() != name || strings.ContainsAny(name, " ,/\\") {
+			add(field, name, "must be a lowercase name without spaces, commas or slashes")
+		}
+		if _, err := (WasmBuildArgs{Mode: p.Mode}).mode(); err != nil {
+			add(field+".mode", p.Mode, "use L, M, S or one of large, dev, medium, debug, small, prod")
+		}
+		checkEnv(field+".env", p.Env)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return Err(strings.Join(problems, "\n"))
+}
+
+// applyConfig copies the set fields into cfg.
+func (pc *ProjectConfig) applyConfig(cfg *Config) {
+	if dir := pc.SourceDir; dir != "" {
+		cfg.SourceDir = func() string { return dir }
+	}
+	if dir := pc.OutputDir; dir != "" {
+		cfg.OutputDir = func() string { return dir }
+	}
+	if pc.AssetsURLPrefix != "" {
+		cfg.AssetsURLPrefix = pc.AssetsURLPrefix
+	}
+	profile, _ := pc.profile(pc.Mode)
+	if args := append(append([]string{}, pc.CompilingArguments...), profile.CompilingArguments...); len(args) > 0 {
+		cfg.CompilingArguments = func() []string { return args }
+	}
+	if env := append(append([]string{}, pc.Env...), profile.Env...); len(env) > 0 {
+		cfg.Env = env
+	}
+	if pc.Reproducible {
+		cfg.Reproducible = true
+	}
+	if pc.Toolchain != "" {
+		cfg.Toolchain = pc.Toolchain
+	}
+	if pc.TemplateDir != "" {
+		cfg.TemplateDir = pc.TemplateDir
+	}
+}
+
+// applyBuildArgs fills the empty fields of a with the file's values: flags
+// and explicit arguments win over tinywasm.json, which wins over defaults.
+// A Mode naming a custom profile is replaced by the profile's mode, and its
+// arguments and env follow the file's (BuildArgs replace only the file's).
+func (pc *ProjectConfig) applyBuildArgs(a WasmBuildArgs) WasmBuildArgs {
+	if a.SourceDir == "" {
+		a.SourceDir = pc.SourceDir
+	}
+	if a.MainFile == "" {
+		a.MainFile = pc.MainFile
+	}
+	if a.OutputDir == "" {
+		a.OutputDir = pc.OutputDir
+	}
+	if a.OutputName == "" {
+		a.OutputName = pc.OutputName
+	}
+	if a.Mode == "" && !a.Stdlib {
+		a.Mode = pc.Mode
+	}
+	profile, ok := pc.profile(a.Mode)
+	if ok {
+		a.Mode = profile.Mode
+	}
+	if len(a.BuildArgs) == 0 {
+		a.BuildArgs = append(append([]string{}, pc.CompilingArguments...), profile.CompilingArguments...)
+	} else {
+		a.BuildArgs = append(append([]string{}, profile.CompilingArguments...), a.BuildArgs...)
+	}
+	if env := append(append([]string{}, pc.Env...), profile.Env...); len(env) > 0 {
+		a.Env = append(env, a.Env...)
+	}
+	if a.MaxSize == 0 && pc.MaxSize != "" {
+		a.MaxSize, _ = ParseByteSize(pc.MaxSize)
+	}
+	a.Reproducible = a.Reproducible || pc.Reproducible
+	if a.Toolchain == "" {
+		a.Toolchain = pc.Toolchain
+	}
+	if a.assetsURLPrefix == "" {
+		a.assetsURLPrefix = pc.AssetsURLPrefix
+	}
+	return a
+}
+
+// ProjectConfigError returns the error of the last tinywasm.json load (New or
+// SetAppRootDir), nil when the file is valid or absent. An invalid file is
+// ignored as a whole, leaving the code-set configuration in place.
+func (w *WasmClient) ProjectConfigError() error {
+	return w.projectConfigErr
+}
+
+// loadProjectConfig applies tinywasm.json from AppRootDir to the client and
+// reports whether it set the mode. The file's mode only sets the initial mode:
+// a mode stored in the Database (the user's last choice) is restored
+// afterwards by loadMode.
+func (w *WasmClient) loadProjectConfig() (modeSet bool) {
+	if w.Config.skipProjectFile {
+		return false
+	}
+	pc, err := LoadProjectConfig(w.AppRootDir)
+	w.projectConfigErr = err
+	if err != nil {
+		w.Logger(err)
+		return false
+	}
+	if w.project == nil {
+		w.codeArgs, w.codeEnv = w.Config.CompilingArguments, w.Config.Env
+	}
+	w.project, w.profile = pc, ""
+	if pc == nil {
+		return false
+	}
+
+	pc.applyConfig(w.Config)
+	if _, ok := pc.profile(pc.Mode); ok {
+		w.profile = Convert(pc.Mode).ToLower().String()
+	}
+	if pc.MainFile != "" {
+		w.MainInputFile = pc.MainFile
+	}
+	if pc.OutputName != "" {
+		w.OutputName = pc.OutputName
+	}
+	// keep the current mode on its shortcut when the file renames it
+	mode := map[string]string{
+		w.buildLargeSizeShortcut:  "L",
+		w.buildMediumSizeShortcut: "M",
+		w.buildSmallSizeShortcut:  "S",
+	}[w.CurrentSizeMode]
+	if pc.Mode != "" {
+		mode, _ = pc.sizeMode(pc.Mode)
+	}
+	w.SetBuildShortcuts(pc.Shortcuts.Large, pc.Shortcuts.Medium, pc.Shortcuts.Small)
+	if mode != "" {
+		w.CurrentSizeMode = w.shortcutOf(mode)
+		w.TinyGoCompilerFlag = w.RequiresTinyGo(w.CurrentSizeMode)
+	}
+	return pc.Mode != ""
+}
+
+// resolveBuildArgs merges tinywasm.json (from the working directory) into args
+// and applies the defaults.
+func resolveBuildArgs(args WasmBuildArgs) (WasmBuildArgs, error) {
+	pc, err := LoadProjectConfig(".")
+	if err != nil {
+		return args.withDefaults(), buildErr(WasmBuildErrConfig, err)
+	}
+	if pc != nil {
+		args = pc.applyBuildArgs(args)
+	}
+	return args.withDefaults(), nil
+}
+
+// profileNames returns the custom profiles of tinywasm.json, sorted.
+func (w *WasmClient) profileNames() []string {
+	if w.project == nil {
+		return nil
+	}
+	names := make([]string, 0, len(w.project.Profiles))
+	for name := range w.project.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveMode resolves name, a mode shortcut or a custom profile of
+// tinywasm.json, to the shortcut it compiles with and the profile ("" for a
+// shortcut), as wasmbuild -mode does.
+func (w *WasmClient) resolveMode(name string) (mode, profile string, err error) {
+	if w.project != nil {
+		if p, ok := w.project.profile(name); ok {
+			size, _ := WasmBuildArgs{Mode: p.Mode}.mode()
+			return w.shortcutOf(size), Convert(name).ToLower().String(), nil
+		}
+	}
+	// Ensure mode is uppercase to match configured shortcuts which are
+	// expected to be single uppercase letters by default.
+	mode = Convert(name).ToUpper().String()
+	valid := []string{
+		Convert(w.buildLargeSizeShortcut).ToUpper().String(),
+		Convert(w.buildMediumSizeShortcut).ToUpper().String(),
+		Convert(w.buildSmallSizeShortcut).ToUpper().String(),
+	}
+	for _, v := range valid {
+		if mode == v {
+			return mode, "", nil
+		}
+	}
+	return "", "", Err("mode", ":", mode, "invalid", "valid", ":", strings.Join(append(valid, w.profileNames()...), ", "))
+}
+
+// shortcutOf returns the shortcut of the size mode L, M or S.
+func (w *WasmClient) shortcutOf(size string) string {
+	return map[string]string{
+		"L": w.buildLargeSizeShortcut,
+		"M": w.buildMediumSizeShortcut,
+		"S": w.buildSmallSizeShortcut,
+	}[size]
+}
+
+// profileCompiler returns the compiler arguments and env of the custom
+// profile name ("" for none): tinywasm.json's plus the profile's, or those
+// set in code when neither sets any, as applyConfig does.
+func (w *WasmClient) profileCompiler(name string) (args func() []string, env []string) {
+	if w.project == nil {
+		return w.Config.CompilingArguments, w.Config.Env
+	}
+	args, env = w.codeArgs, w.codeEnv
+	p, _ := w.project.profile(name)
+	if a := append(append([]string{}, w.project.CompilingArguments...), p.CompilingArguments...); len(a) > 0 {
+		args = func() []string { return a }
+	}
+	if e := append(append([]string{}, w.project.Env...), p.Env...); len(e) > 0 {
+		env = e
+	}
+	return args, env
+}
+
+// useProfile switches the compiler arguments and env to those of the custom
+// profile name ("" for none), rebuilding the builders when it changes.
+func (w *WasmClient) useProfile(name string) {
+	w.storageMu.Lock()
+	defer w.storageMu.Unlock()
+	if name == w.profile {
+		return
+	}
+	w.profile = name
+	w.Config.CompilingArguments, w.Config.Env = w.profileCompiler(name)
+	w.builderWasmInit()
+}
+
+// activeProfile returns the custom profile in use, "" for none.
+func (w *WasmClient) activeProfile() string {
+	w.storageMu.RLock()
+	defer w.storageMu.RUnlock()
+	return w.profile
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// Storage names accepted by SetStorage.
+const (
+	StorageMemory = "memory" // the binary is compiled into memory and served from there
+	StorageDisk   = "disk"   // the binary is written to OutputRelativePath and served from disk
+)
+
+// ProjectLayout describes where the wasm frontend lives and how it is served.
+type ProjectLayout struct {
+	Root           string `json:"root"`                  // AppRootDir
+	SourceDir      string `json:"source_dir"`            // relative to root, eg: web
+	MainFile       string `json:"main_file"`             // MainInputFileRelativePath, eg: web/client.go
+	MainFileExists bool   `json:"main_file_exists"`      // false until the entry point is generated or written
+	OutputFile     string `json:"output_file"`           // OutputRelativePath, eg: web/public/client.wasm
+	Route          string `json:"route"`                 // URL path the binary is served under, eg: /client.wasm
+	Storage        string `json:"storage"`               // StorageMemory or StorageDisk
+	Mode           string `json:"mode"`                  // current mode shortcut
+	ConfigFile     string `json:"config_file,omitempty"` // tinywasm.json when present
+}
+
+// Summary returns a short human readable description of the layout.
+func (l *ProjectLayout) Summary() string {
+	var b strings.Builder
+	b.WriteString("Entry point: " + l.MainFile)
+	if !l.MainFileExists {
+		b.WriteString(" (missing: generate it with wasm_generate_client)")
+	}
+	b.WriteString("\nBinary: " + l.Route + " (mode " + l.Mode + ", ")
+	if l.Storage == StorageDisk {
+		b.WriteString("written to " + l.OutputFile + ")")
+	} else {
+		b.WriteString("served from memory)")
+	}
+	if l.ConfigFile != "" {
+		b.WriteString("\nConfiguration: " + l.ConfigFile)
+	}
+	return b.String()
+}
+
+// Layout returns the current project layout of the wasm frontend.
+func (w *WasmClient) Layout() *ProjectLayout {
+	l := &ProjectLayout{
+		Root:      w.AppRootDir,
+		SourceDir: w.Config.SourceDir(),
+		MainFile:  w.MainInputFileRelativePath(),
+		Route:     w.wasmRoutePath(),
+		Mode:      w.Value(),
+	}
+	_, err := os.Stat(filepath.Join(w.AppRootDir, l.SourceDir, w.MainInputFile))
+	l.MainFileExists = err == nil
+	if _, err := os.Stat(filepath.Join(w.AppRootDir, ProjectConfigFile)); err == nil {
+		l.ConfigFile = ProjectConfigFile
+	}
+
+	w.storageMu.RLock()
+	defer w.storageMu.RUnlock()
+	l.OutputFile = w.OutputRelativePath()
+	l.Storage = StorageMemory
+	if w.storageMode() == "disk" {
+		l.Storage = StorageDisk
+	}
+	return l
+}
+
+// SetStorage switches to StorageMemory or StorageDisk and, when it changed,
+// recompiles so the new storage holds the binary. It reports whether the
+// storage changed; the error is an unknown name or the compile failure.
+// Routes registered by RegisterRoutes serve from the new storage at once.
+func (w *WasmClient) SetStorage(name string) (bool, error) {
+	w.storageMu.RLock()
+	current := w.storageMode()
+	w.storageMu.RUnlock()
+
+	switch name {
+	case StorageMemory:
+		if current == "mem" {
+			return false, nil
+		}
+		w.UseMemoryStorage()
+	case StorageDisk:
+		if current == "disk" {
+			return false, nil
+		}
+		w.UseDiskStorage()
+	default:
+		return false, Errf("unknown storage %q, use %s or %s", name, StorageMemory, StorageDisk)
+	}
+	return true, w.RecompileMainWasm()
+}
@@ -0,0 +1,318 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// reproducibleGoArgs rewrites the go build arguments of a reproducible build:
+// -trimpath drops the directory of the checkout, -buildvcs=false the VCS stamp
+// and an empty -buildid the content hash of the inputs' paths. The ldflags
+// (including the -X values gobuild would collect) are merged into a single
+// -ldflags, since go build keeps only the last one.
+func reproducibleGoArgs(args []string) []string {
+	var out, ldflags []string
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "-ldflags" && i+1 < len(args):
+			ldflags = append(ldflags, args[i+1])
+			i++
+		case strings.HasPrefix(a, "-ldflags="):
+			ldflags = append(ldflags, strings.TrimPrefix(a, "-ldflags="))
+		case a == "-X" && i+1 < len(args):
+			ldflags = append(ldflags, "-X "+args[i+1])
+			i++
+		case strings.HasPrefix(a, "-X"):
+			ldflags = append(ldflags, a)
+		case a == "-trimpath" || strings.HasPrefix(a, "-buildvcs"):
+		default:
+			out = append(out, a)
+		}
+	}
+	ldflags = append(ldflags, "-buildid=")
+	return append(out, "-trimpath", "-buildvcs=false", "-ldflags="+strings.Join(ldflags, " "))
+}
+
+// reproducibleEnv pins GOTOOLCHAIN unless env already sets it.
+func (w *WasmClient) reproducibleEnv(env []string) []string {
+	if containsEnvKey(env, "GOTOOLCHAIN") {
+		return nil
+	}
+	return []string{"GOTOOLCHAIN=" + reproducibleToolchain(w.AppRootDir, w.Toolchain)}
+}
+
+// reproducibleToolchain returns toolchain, else the toolchain directive of
+// go.mod in rootDir, else "local": the installed Go, never switched
+// automatically.
+func reproducibleToolchain(rootDir, toolchain string) string {
+	if toolchain != "" {
+		return toolchain
+	}
+	data, err := os.ReadFile(filepath.Join(rootDir, "go.mod"))
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "toolchain" {
+				return fields[1]
+			}
+		}
+	}
+	return "local"
+}
+
+// wasmSectionNames names the wasm section ids.
+var wasmSectionNames = []string{"custom", "type", "import", "function", "table", "memory", "global", "export", "start", "element", "code", "data", "datacount", "tag"}
+
+// wasmSection is one section of a wasm binary.
+type wasmSection struct {
+	id         byte
+	name       string // custom section name, eg: "name"
+	start, end int    // byte range of the whole section, id included
+	body       int    // start of the content, after the size
+}
+
+// label returns the section name, "custom:<name>" for custom sections.
+func (s wasmSection) label() string {
+	name := "unknown(" + strconv.Itoa(int(s.id)) + ")"
+	if int(s.id) < len(wasmSectionNames) {
+		name = wasmSectionNames[s.id]
+	}
+	if s.id == 0 {
+		name += ":" + s.name
+	}
+	return name
+}
+
+// readULEB reads an unsigned LEB128 at b[i:] and returns it with the next index.
+func readULEB(b []byte, i int) (uint64, int, error) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if i >= len(b) {
+			return 0, i, Err("truncated LEB128")
+		}
+		c := b[i]
+		i++
+		v |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v, i, nil
+		}
+	}
+	return 0, i, Err("LEB128 overflow")
+}
+
+// readWasmName reads a length-prefixed name at b[i:].
+func readWasmName(b []byte, i int) (string, int, error) {
+	n, i, err := readULEB(b, i)
+	if err != nil || uint64(len(b)-i) < n {
+		return "", i, Err("truncated name")
+	}
+	return string(b[i : i+int(n)]), i + int(n), nil
+}
+
+// wasmSections splits a wasm binary into its sections.
+func wasmSections(b []byte) ([]wasmSection, error) {
+	if len(b) < 8 || string(b[:4]) != "\x00asm" {
+		return nil, Err("not a wasm binary")
+	}
+	var sections []wasmSection
+	for i := 8; i < len(b); {
+		s := wasmSection{id: b[i], start: i}
+		size, body, err := readULEB(b, i+1)
+		if err != nil || uint64(len(b)-body) < size {
+			return sections, Errf("section at offset %d is truncated", i)
+		}
+		s.body, s.end = body, body+int(size)
+		if s.id == 0 {
+			s.name, _, _ = readWasmName(b[:s.end], body)
+		}
+		sections = append(sections, s)
+		i = s.end
+	}
+	return sections, nil
+}
+
+// WasmSectionDiff locates the first difference between two wasm binaries.
+type WasmSectionDiff struct {
+	Offset   int    `json:"offset"`             // first differing byte
+	Section  string `json:"section"`            // eg: "code", "custom:name", "header"
+	Index    int    `json:"index"`              // position of the section in the binary, -1 for the header
+	SizeA    int    `json:"size_a"`             // section size in the first build
+	SizeB    int    `json:"size_b"`             // section size in the second build
+	Function string `json:"function,omitempty"` // first differing function of the code section, eg: "#42 main.main"
+}
+
+// String describes the difference in one line.
+func (d *WasmSectionDiff) String() string {
+	s := "first difference at byte " + strconv.Itoa(d.Offset) + " in section " + d.Section
+	if d.Index >= 0 {
+		s += " (#" + strconv.Itoa(d.Index) + ", " + strconv.Itoa(d.SizeA) + " vs " + strconv.Itoa(d.SizeB) + " bytes)"
+	}
+	if d.Function != "" {
+		s += ", function " + d.Function
+	}
+	return s
+}
+
+// diffWasm returns the first difference between a and b, nil when equal.
+func diffWasm(a, b []byte) *WasmSectionDiff {
+	off := 0
+	for off < len(a) && off < len(b) && a[off] == b[off] {
+		off++
+	}
+	if off == len(a) && off == len(b) {
+		return nil
+	}
+	d := &WasmSectionDiff{Offset: off, Section: "header", Index: -1}
+	if off < 8 {
+		return d
+	}
+	secA, _ := wasmSections(a)
+	secB, _ := wasmSections(b)
+	for i, s := range secA {
+		if off >= s.end && i < len(secA)-1 {
+			continue
+		}
+		d.Section, d.Index, d.SizeA = s.label(), i, s.end-s.start
+		if i < len(secB) {
+			d.SizeB = secB[i].end - secB[i].start
+		}
+		if s.id == 10 {
+			d.Function = wasmFunctionAt(a, secA, s, off)
+		}
+		return d
+	}
+	return d
+}
+
+// wasmFunctionAt returns "#<index> <name>" of the code section body holding
+// off; the name comes from the name section when present.
+func wasmFunctionAt(b []byte, sections []wasmSection, code wasmSection, off int) string {
+	count, i, err := readULEB(b[:code.end], code.body)
+	if err != nil {
+		return ""
+	}
+	local := -1
+	for n := 0; n < int(count); n++ {
+		size, body, err := readULEB(b[:code.end], i)
+		if err != nil {
+			return ""
+		}
+		if off < body+int(size) {
+			local = n
+			break
+		}
+		i = body + int(size)
+	}
+	if local < 0 {
+		return ""
+	}
+	index := wasmImportedFuncs(b, sections) + local
+	label := "#" + strconv.Itoa(index)
+	if name := wasmFunctionName(b, sections, index); name != "" {
+		label += " " + name
+	}
+	return label
+}
+
+// wasmImportedFuncs counts the imported functions, which come first in the
+// function index space.
+func wasmImportedFuncs(b []byte, sections []wasmSection) int {
+	for _, s := range sections {
+		if s.id != 2 {
+			continue
+		}
+		b := b[:s.end]
+		count, i, err := readULEB(b, s.body)
+		if err != nil {
+			return 0
+		}
+		funcs := 0
+		for n := 0; n < int(count); n++ {
+			if _, i, err = readWasmName(b, i); err != nil {
+				return funcs
+			}
+			if _, i, err = readWasmName(b, i); err != nil || i >= len(b) {
+				return funcs
+			}
+			kind := b[i]
+			i++
+			switch kind {
+			case 0: // func: type index
+				funcs++
+				_, i, err = readULEB(b, i)
+			case 1: // table: reftype + limits
+				i, err = skipWasmLimits(b, i+1)
+			case 2: // memory: limits
+				i, err = skipWasmLimits(b, i)
+			case 3: // global: valtype + mutability
+				i += 2
+			case 4: // tag: attribute + type index
+				_, i, err = readULEB(b, i+1)
+			}
+			if err != nil {
+				return funcs
+			}
+		}
+		return funcs
+	}
+	return 0
+}
+
+// skipWasmLimits skips a limits entry: flags, min and an optional max.
+func skipWasmLimits(b []byte, i int) (int, error) {
+	if i >= len(b) {
+		return i, Err("truncated limits")
+	}
+	flags := b[i]
+	_, i, err := readULEB(b, i+1)
+	if err == nil && flags&1 != 0 {
+		_, i, err = readULEB(b, i)
+	}
+	return i, err
+}
+
+// wasmFunctionName looks index up in the function names of the name section.
+func wasmFunctionName(b []byte, sections []wasmSection, index int) string {
+	return wasmFunctionNames(b, sections)[index]
+}
+
+// wasmFunctionNames returns the function names of the name section by
+// function index, nil when the binary has none (eg: TinyGo -no-debug).
+func wasmFunctionNames(b []byte, sections []wasmSection) map[int]string {
+	for _, s := range sections {
+		if s.id != 0 || s.name != "name" {
+			continue
+		}
+		b := b[:s.end]
+		_, i, _ := readWasmName(b, s.body)
+		for i < len(b) {
+			sub := b[i]
+			size, body, err := readULEB(b, i+1)
+			if err != nil {
+				return nil
+			}
+			i = body + int(size)
+			if sub != 1 {
+				continue
+			}
+			count, j, err := readULEB(b, body)
+			names := make(map[int]string, count)
+			for n := 0; err == nil && n < int(count); n++ {
+				var idx uint64
+				var name string
+				if idx, j, err = readULEB(b, j); err != nil {
+					break
+				}
+				if name, j, err = readWasmName(b, j); err != nil {
+					break
+				}
+				names[int(idx)] = name
+			}
+			return names
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tinywasm/model"
+	"github.com/tinywasm/router"
+)
+
+// HTTPRouter is a net/http implementation of router.Router for the wasmbuild
+// dev server: it mounts RegisterRoutes and static directories on an
+// http.ServeMux. Routes are private until Public() or Requires() is chained;
+// Requires is checked with Authorize (nil denies). Sockets are not supported.
+type HTTPRouter struct {
+	Authorize model.Authorizer // decides Requires() routes; nil denies them
+
+	mux         *http.ServeMux
+	mu          sync.RWMutex
+	routes      []*httpRoute
+	middlewares []router.Middleware
+}
+
+// NewHTTPRouter returns an empty HTTPRouter.
+func NewHTTPRouter() *HTTPRouter {
+	return &HTTPRouter{mux: http.NewServeMux()}
+}
+
+// ServeHTTP implements http.Handler.
+func (r *HTTPRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(rw, req)
+}
+
+// httpRoute records the permission annotations chained after registration.
+type httpRoute struct {
+	mu   sync.RWMutex
+	info router.RouteInfo
+}
+
+func (rt *httpRoute) Requires(resource model.Resource, action model.Action) router.Route {
+	rt.mu.Lock()
+	rt.info.Resource, rt.info.Action = resource, action
+	rt.mu.Unlock()
+	return rt
+}
+
+func (rt *httpRoute) Public() router.Route {
+	rt.mu.Lock()
+	rt.info.Public = true
+	rt.mu.Unlock()
+	return rt
+}
+
+// muxPattern converts a router path ("/api/orders/:id") to a ServeMux pattern ("GET /api/orders/{id}").
+func muxPattern(method, p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// register adds a route. The permission check runs inside the middlewares, so
+// an authentication middleware can SetUserID first; streams and sockets skip
+// the middlewares, which could not keep the Streamer.
+func (r *HTTPRouter) register(method, p string, public, middlewares bool, h router.HandlerFunc) *httpRoute {
+	rt := &httpRoute{info: router.RouteInfo{Method: method, Path: p, Public: public}}
+	r.mu.Lock()
+	r.routes = append(r.routes, rt)
+	r.mu.Unlock()
+
+	guarded := func(c router.Context) {
+		if !r.allowed(rt, c.UserID()) {
+			c.WriteStatus(http.StatusForbidden)
+			c.Write([]byte("forbidden"))
+			return
+		}
+		h(c)
+	}
+	r.mux.HandleFunc(muxPattern(method, p), func(rw http.ResponseWriter, req *http.Request) {
+		ctx := newHTTPContext(rw, req)
+		if middlewares {
+			r.chain(guarded)(ctx)
+			return
+		}
+		guarded(ctx)
+	})
+	return rt
+}
+
+// allowed reports whether userID may use rt: public routes always, Requires routes via Authorize.
+func (r *HTTPRouter) allowed(rt *httpRoute, userID string) bool {
+	rt.mu.RLock()
+	info := rt.info
+	rt.mu.RUnlock()
+	if info.Public {
+		return true
+	}
+	if info.Resource == "" {
+		return false // neither Public() nor Requires(): closed
+	}
+	return model.Allowed(r.Authorize, userID, info.Resource, info.Action)
+}
+
+// chain wraps h with the middlewares registered so far, in registration order.
+func (r *HTTPRouter) chain(h router.HandlerFunc) router.HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	return h
+}
+
+func (r *HTTPRouter) Get(p string, h router.HandlerFunc) router.Route {
+	return r.Handle(http.MethodGet, p, h)
+}
+
+func (r *HTTPRouter) Post(p string, h router.HandlerFunc) router.Route {
+	return r.Handle(http.MethodPost, p, h)
+}
+
+func (r *HTTPRouter) Put(p string, h router.HandlerFunc) router.Route {
+	return r.Handle(http.MethodPut, p, h)
+}
+
+func (r *HTTPRouter) Delete(p string, h router.HandlerFunc) router.Route {
+	return r.Handle(http.MethodDelete, p, h)
+}
+
+func (r *HTTPRouter) Options(p string, h router.HandlerFunc) router.Route {
+	return r.Handle(http.MethodOptions, p, h)
+}
+
+func (r *HTTPRouter) Handle(method, p string, h router.HandlerFunc) router.Route {
+	return r.register(method, p, false, true, h)
+}
+
+func (r *HTTPRouter) Stream(p string, h router.StreamFunc) router.Route {
+	return r.register(http.MethodGet, p, false, false, func(c router.Context) {
+		h(c.(*httpContext))
+	})
+}
+
+// Socket registers a route answering 501: WebSocket upgrades are outside the dev server.
+func (r *HTTPRouter) Socket(p string, h router.SocketFunc) router.Route {
+	return r.register(http.MethodGet, p, false, false, func(c router.Context) {
+		c.WriteStatus(http.StatusNotImplemented)
+		c.Write([]byte("websocket not supported by the dev server"))
+	})
+}
+
+func (r *HTTPRouter) PublicAsset(p string, h router.HandlerFunc) {
+	r.register(http.MethodGet, p, true, true, h)
+}
+
+// PublicDir serves the files of dir under prefix ("/" serves the site root),
+// with index.html for directories and no caching.
+func (r *HTTPRouter) PublicDir(prefix string, dir string) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	pattern := strings.TrimSuffix(prefix, "/") + "/{file...}"
+	rt := r.register(http.MethodGet, pattern, true, true, func(c router.Context) {
+		serveFile(c, dir, strings.TrimPrefix(c.Path(), prefix))
+	})
+	rt.info.Path = prefix
+	rt.info.Dir = dir
+}
+
+// serveFile writes dir/name to c, refusing paths that leave dir.
+func serveFile(c router.Context, dir, name string) {
+	name = path.Clean("/" + name)
+	full := filepath.Join(dir, filepath.FromSlash(name))
+	if info, err := os.Stat(full); err == nil && info.IsDir() {
+		full = filepath.Join(full, "index.html")
+	}
+	content, err := os.ReadFile(full)
+	if err != nil {
+		c.WriteStatus(http.StatusNotFound)
+		c.Write([]byte("404 page not found"))
+		return
+	}
+	if ct := mime.TypeByExtension(filepath.Ext(full)); ct != "" {
+		c.SetHeader("Content-Type", ct)
+	}
+	c.SetHeader("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Write(content)
+}
+
+func (r *HTTPRouter) Use(m ...router.Middleware) {
+	r.mu.Lock()
+	r.middlewares = append(r.middlewares, m...)
+	r.mu.Unlock()
+}
+
+func (r *HTTPRouter) Routes() []router.RouteInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]router.RouteInfo, 0, len(r.routes))
+	for _, rt := range r.routes {
+		rt.mu.RLock()
+		out = append(out, rt.info)
+		rt.mu.RUnlock()
+	}
+	return out
+}
+
+// httpContext adapts one net/http request to router.Context and router.Streamer.
+type httpContext struct {
+	rw     http.ResponseWriter
+	req    *http.Request
+	body   []byte
+	read   bool
+	values map[string]any
+	userID string
+}
+
+func newHTTPContext(rw http.ResponseWriter, req *http.Request) *httpContext {
+	return &httpContext{rw: rw, req: req}
+}
+
+func (c *httpContext) Method() string { return c.req.Method }
+func (c *httpContext) Path() string   { return c.req.URL.Path }
+
+func (c *httpContext) Body() []byte {
+	if !c.read {
+		c.body, _ = io.ReadAll(c.req.Body)
+		c.read = true
+	}
+	return c.body
+}
+
+func (c *httpContext) GetHeader(key string) string { return c.req.Header.Get(key) }
+func (c *httpContext) SetHeader(key, value string) { c.rw.Header().Set(key, value) }
+func (c *httpContext) WriteStatus(code int)        { c.rw.WriteHeader(code) }
+func (c *httpContext) Write(b []byte) (int, error) { return c.rw.Write(b) }
+func (c *httpContext) SetUserID(id string)         { c.userID = id }
+func (c *httpContext) UserID() string              { return c.userID }
+func (c *httpContext) Value(key string) any        { return c.values[key] }
+
+func (c *httpContext) SetValue(key string, v any) {
+	if c.values == nil {
+		c.values = map[string]any{}
+	}
+	c.values[key] = v
+}
+
+// Done is closed when the client disconnects, so long streams can stop early.
+func (c *httpContext) Done() <-chan struct{} {
+	return c.req.Context().Done()
+}
+
+func (c *httpContext) Flush() {
+	if f, ok := c.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+var sameSiteToHTTP = map[router.SameSite]http.SameSite{
+	router.SameSiteDefault: http.SameSiteDefaultMode,
+	router.SameSiteLax:     http.SameSiteLaxMode,
+	router.SameSiteStrict:  http.SameSiteStrictMode,
+	router.SameSiteNone:    http.SameSiteNoneMode,
+}
+
+func (c *httpContext) SetCookie(ck router.Cookie) {
+	http.SetCookie(c.rw, &http.Cookie{
+		Name: ck.Name, Value: ck.Value, Path: ck.Path, Domain: ck.Domain,
+		MaxAge: ck.MaxAge, Secure: ck.Secure, HttpOnly: ck.HttpOnly,
+		SameSite: sameSiteToHTTP[ck.SameSite],
+	})
+}
+
+func (c *httpContext) Cookie(name string) (router.Cookie, bool) {
+	ck, err := c.req.Cookie(name)
+	if err != nil {
+		return router.Cookie{}, false
+	}
+	return router.Cookie{Name: ck.Name, Value: ck.Value}, true
+}
+
+var (
+	_ router.Router   = (*HTTPRouter)(nil)
+	_ router.Streamer = (*httpContext)(nil)
+)
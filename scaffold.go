@@ -0,0 +1,196 @@
+package client
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// TemplateVars are the values of the {{placeholders}} of a client template.
+// An empty field takes its default (see DefaultTemplateVars).
+type TemplateVars struct {
+	AppName    string `json:"app_name"`    // {{app_name}}, default: last element of the module path
+	MountID    string `json:"mount_id"`    // {{mount_id}}, id of the element the app renders into, default "app"
+	ModulePath string `json:"module_path"` // {{module_path}}, default: go.mod's module
+	OutputName string `json:"output_name"` // {{output_name}}, .wasm file name without extension
+}
+
+// DefaultTemplateVars returns the template variables of the project:
+// go.mod's module path, its last element as app name (the root directory's
+// name without go.mod), mount id "app" and the client's OutputName.
+func (w *WasmClient) DefaultTemplateVars() TemplateVars {
+	v := TemplateVars{MountID: "app", OutputName: w.OutputName}
+	if data, err := os.ReadFile(filepath.Join(w.AppRootDir, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+				v.ModulePath = strings.Trim(fields[1], `"`)
+				break
+			}
+		}
+	}
+	v.AppName = path.Base(v.ModulePath)
+	if v.ModulePath == "" {
+		abs, _ := filepath.Abs(w.AppRootDir)
+		v.AppName = filepath.Base(abs)
+	}
+	return v
+}
+
+// withDefaults fills the empty fields of v from def; an empty AppName takes
+// the last element of v.ModulePath when set.
+func (v TemplateVars) withDefaults(def TemplateVars) TemplateVars {
+	if v.AppName == "" && v.ModulePath != "" {
+		v.AppName = path.Base(v.ModulePath)
+	}
+	if v.AppName == "" {
+		v.AppName = def.AppName
+	}
+	if v.MountID == "" {
+		v.MountID = def.MountID
+	}
+	if v.ModulePath == "" {
+		v.ModulePath = def.ModulePath
+	}
+	if v.OutputName == "" {
+		v.OutputName = def.OutputName
+	}
+	return v
+}
+
+// validate rejects values that would break the Go, JS or CSS string literals
+// they are placed in.
+func (v TemplateVars) validate() error {
+	for name, value := range map[string]string{
+		"app_name": v.AppName, "mount_id": v.MountID, "module_path": v.ModulePath, "output_name": v.OutputName,
+	} {
+		if strings.ContainsAny(value, "\"'`\\\n\r") {
+			return Errf("template variable %s %q: quotes, backslashes and line breaks are not allowed", name, value)
+		}
+	}
+	return nil
+}
+
+// replacer substitutes the placeholders; unknown ones are left as written.
+func (v TemplateVars) replacer() *strings.Replacer {
+	return strings.NewReplacer(
+		"{{app_name}}", v.AppName,
+		"{{mount_id}}", v.MountID,
+		"{{module_path}}", v.ModulePath,
+		"{{output_name}}", v.OutputName,
+	)
+}
+
+// ScaffoldOptions are the arguments of Scaffold.
+type ScaffoldOptions struct {
+	Template      string       // template name, "" selects DefaultClientTemplate
+	Vars          TemplateVars // placeholder values, empty fields take DefaultTemplateVars
+	SkipIDEConfig bool         // leave .vscode/settings.json alone
+}
+
+// ScaffoldResult reports what Scaffold wrote. Paths are relative to AppRootDir.
+type ScaffoldResult struct {
+	Template string       `json:"template"`
+	Vars     TemplateVars `json:"vars"`
+	Created  []string     `json:"created"`           // files written
+	Skipped  []string     `json:"skipped,omitempty"` // files that already existed, left untouched
+	Modules  []string     `json:"modules,omitempty"` // modules added to go.mod
+	// BuildError is the compile error of the generated client, "" when it
+	// compiled (or was not compiled).
+	BuildError string `json:"build_error,omitempty"`
+}
+
+// Summary returns one line per created and skipped file.
+func (r *ScaffoldResult) Summary() string {
+	var b strings.Builder
+	b.WriteString("Template " + r.Template + ":")
+	for _, f := range r.Created {
+		b.WriteString("\n✅ created " + f)
+	}
+	for _, f := range r.Skipped {
+		b.WriteString("\n⏭️ skipped " + f + " (already exists)")
+	}
+	return b.String()
+}
+
+// Scaffold writes the files of a template into the source directory: the
+// entry point as the main input file, plus the other files it declares. Each
+// placeholder is replaced by opts.Vars. Existing files are never overwritten:
+// they are skipped and listed in the result, the others are still written.
+// When something was written the template's modules are added to go.mod and
+// the client is compiled so In-Memory mode has content to serve; a compile
+// failure is not returned but kept in the result's BuildError and in
+// BuildStatus like any other build. When
+// every file already exists nothing happens and an error says so.
+// PlanScaffold shows the same steps without running them.
+func (w *WasmClient) Scaffold(opts ScaffoldOptions) (*ScaffoldResult, error) {
+	plan, err := w.PlanScaffold(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScaffoldResult{Template: plan.Template, Vars: plan.Vars, Created: []string{}, Skipped: append([]string{}, plan.Skip...)}
+	if len(plan.Create) == 0 {
+		return result, Errf("nothing generated: %s already exist, existing files are never overwritten", strings.Join(plan.Skip, ", "))
+	}
+
+	for _, f := range plan.Create {
+		name := filepath.Join(w.AppRootDir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return result, err
+		}
+		// O_EXCL: a file created since the plan is skipped, never overwritten
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if os.IsExist(err) {
+			result.Skipped = append(result.Skipped, f.Path)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		_, err = file.WriteString(f.Content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, f.Path)
+		w.LogSuccessState("Generated WASM source file at", name)
+	}
+	if len(result.Created) == 0 {
+		return result, Errf("nothing generated: %s already exist, existing files are never overwritten", strings.Join(result.Skipped, ", "))
+	}
+
+	if !opts.SkipIDEConfig {
+		w.VisualStudioCodeWasmEnvConfig()
+	}
+
+	// Ensure dependencies are present before compiling
+	modules := make([]string, len(plan.Modules))
+	for i, m := range plan.Modules {
+		modules[i] = m.Module + "@" + m.Version
+	}
+	if err := w.ensureTemplateDependencies(modules); err != nil {
+		w.recordBuild(time.Now(), err)
+		return result, Errf("error ensuring template dependencies: %w", err)
+	}
+	for _, m := range plan.Modules {
+		result.Modules = append(result.Modules, m.Module)
+	}
+
+	// Trigger compilation immediately so In-Memory mode has content to serve
+	w.storageMu.RLock()
+	store := w.Storage
+	w.storageMu.RUnlock()
+
+	if store != nil {
+		if err := w.compileStorage(store); err != nil {
+			w.Logger("Error compiling generated client:", err)
+			result.BuildError = err.Error()
+		}
+	}
+	return result, nil
+}
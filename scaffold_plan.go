@@ -0,0 +1,198 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// PlannedFile is a file Scaffold would create.
+type PlannedFile struct {
+	Path    string `json:"path"` // relative to AppRootDir
+	Content string `json:"content"`
+}
+
+// ModuleChange is a go get Scaffold would run.
+type ModuleChange struct {
+	Module   string `json:"module"`             // eg: github.com/tinywasm/dom
+	Version  string `json:"version"`            // requested version, eg: latest
+	Required string `json:"required,omitempty"` // version go.mod requires now, "" when go get adds it
+	Command  string `json:"command"`            // eg: go get github.com/tinywasm/dom@latest
+}
+
+// ConfigChange is a configuration file Scaffold would write.
+type ConfigChange struct {
+	Path   string `json:"path"`   // relative to AppRootDir
+	Before string `json:"before"` // "" when the file does not exist yet
+	After  string `json:"after"`
+}
+
+// CompileStep is the build Scaffold runs once the files are written.
+type CompileStep struct {
+	Mode     string `json:"mode"`
+	Compiler string `json:"compiler"` // go or tinygo
+	Storage  string `json:"storage"`  // StorageMemory or StorageDisk
+	Output   string `json:"output"`   // route served from memory, or the file written to disk
+}
+
+// ScaffoldPlan is what Scaffold would do, computed without writing anything
+// or running go get.
+type ScaffoldPlan struct {
+	Template  string         `json:"template"`
+	Vars      TemplateVars   `json:"vars"`
+	Create    []PlannedFile  `json:"create"`
+	Skip      []string       `json:"skip,omitempty"` // files that already exist, left untouched
+	Modules   []ModuleChange `json:"modules,omitempty"`
+	IDEConfig *ConfigChange  `json:"ide_config,omitempty"` // nil when skipped or already configured
+	Compile   *CompileStep   `json:"compile,omitempty"`    // nil without a storage to compile into
+}
+
+// Diff returns the unified diff of the files the plan creates and of the IDE
+// configuration. The go.mod changes depend on what go get resolves and are
+// only listed by Summary.
+func (p *ScaffoldPlan) Diff() string {
+	var b strings.Builder
+	for _, f := range p.Create {
+		b.WriteString(unifiedDiff("/dev/null", "b/"+f.Path, nil, []byte(f.Content)))
+	}
+	if c := p.IDEConfig; c != nil {
+		from := "a/" + c.Path
+		if c.Before == "" {
+			from = "/dev/null"
+		}
+		b.WriteString(unifiedDiff(from, "b/"+c.Path, []byte(c.Before), []byte(c.After)))
+	}
+	return b.String()
+}
+
+// Summary returns one line per step of the plan, in the order Scaffold runs them.
+func (p *ScaffoldPlan) Summary() string {
+	var b strings.Builder
+	b.WriteString("Template " + p.Template + " would:")
+	if len(p.Create) == 0 {
+		b.WriteString("\n  generate nothing: every file already exists")
+	}
+	for _, f := range p.Create {
+		b.WriteString("\n  create " + f.Path)
+	}
+	for _, f := range p.Skip {
+		b.WriteString("\n  skip " + f + " (already exists)")
+	}
+	if len(p.Create) == 0 {
+		return b.String()
+	}
+	if c := p.IDEConfig; c != nil {
+		verb := "update "
+		if c.Before == "" {
+			verb = "create "
+		}
+		b.WriteString("\n  " + verb + c.Path + " (GOOS=js GOARCH=wasm for gopls)")
+	}
+	for _, m := range p.Modules {
+		b.WriteString("\n  run " + m.Command)
+		if m.Required == "" {
+			b.WriteString(" (adds it to go.mod)")
+		} else {
+			b.WriteString(" (go.mod requires " + m.Required + " now)")
+		}
+	}
+	if c := p.Compile; c != nil {
+		b.WriteString("\n  compile mode " + c.Mode + " with " + c.Compiler + ", ")
+		if c.Storage == StorageDisk {
+			b.WriteString("written to " + c.Output)
+		} else {
+			b.WriteString("served from memory at " + c.Output)
+		}
+	}
+	return b.String()
+}
+
+// PlanScaffold returns what Scaffold(opts) would do: the files it creates and
+// skips, the go get commands, the .vscode/settings.json change and the compile
+// that follows. Nothing is written; the errors are those of Scaffold.
+func (w *WasmClient) PlanScaffold(opts ScaffoldOptions) (*ScaffoldPlan, error) {
+	tpl, err := w.FindTemplate(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+	vars := opts.Vars.withDefaults(w.DefaultTemplateVars())
+	if err := vars.validate(); err != nil {
+		return nil, err
+	}
+
+	// Read the template markdown and fill in the variables
+	raw, err := tpl.read()
+	if err != nil {
+		return nil, Errf("error reading template %s: %w", tpl.Name, err)
+	}
+	files, err := tpl.render(vars.replacer().Replace(string(raw)), w.MainInputFile)
+	if err != nil {
+		return nil, Errf("template %s: %w", tpl.Name, err)
+	}
+
+	plan := &ScaffoldPlan{Template: tpl.Name, Vars: vars, Create: []PlannedFile{}}
+	for _, f := range files {
+		rel := PathJoin(w.Config.SourceDir(), filepath.ToSlash(f.Path)).String()
+		if _, err := os.Stat(filepath.Join(w.AppRootDir, filepath.FromSlash(rel))); err == nil {
+			plan.Skip = append(plan.Skip, rel)
+			continue
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		plan.Create = append(plan.Create, PlannedFile{Path: rel, Content: f.Content})
+	}
+
+	if !opts.SkipIDEConfig && (w.ShouldCreateIDEConfig == nil || w.ShouldCreateIDEConfig()) {
+		_, before, after, err := w.vscodeSettings()
+		if err != nil {
+			return nil, err
+		}
+		if string(before) != string(after) {
+			plan.IDEConfig = &ConfigChange{Path: ".vscode/settings.json", Before: string(before), After: string(after)}
+		}
+	}
+
+	for _, mod := range tpl.Modules {
+		path, version, ok := strings.Cut(mod, "@")
+		if !ok {
+			version = "latest"
+		}
+		plan.Modules = append(plan.Modules, ModuleChange{
+			Module:   path,
+			Version:  version,
+			Required: w.requiredVersion(path),
+			Command:  "go get " + path + "@" + version,
+		})
+	}
+
+	w.storageMu.RLock()
+	hasStorage := w.Storage != nil
+	w.storageMu.RUnlock()
+	if hasStorage {
+		l := w.Layout()
+		c := &CompileStep{Mode: l.Mode, Compiler: "go", Storage: l.Storage, Output: l.Route}
+		if w.RequiresTinyGo(l.Mode) {
+			c.Compiler = "tinygo"
+		}
+		if l.Storage == StorageDisk {
+			c.Output = l.OutputFile
+		}
+		plan.Compile = c
+	}
+	return plan, nil
+}
+
+// PlanDefaultWasmFileClient returns what CreateDefaultWasmFileClientIfNotExist
+// would do, or nil when it would do nothing: generation is disabled by
+// ShouldGenerateDefaultFile or the main input file exists.
+func (w *WasmClient) PlanDefaultWasmFileClient(skipIDEConfig bool) (*ScaffoldPlan, error) {
+	if w.ShouldGenerateDefaultFile != nil && !w.ShouldGenerateDefaultFile() {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(w.AppRootDir, w.Config.SourceDir(), w.MainInputFile)); !os.IsNotExist(err) {
+		return nil, nil
+	}
+	return w.PlanScaffold(ScaffoldOptions{Template: DefaultClientTemplate, SkipIDEConfig: skipIDEConfig})
+}
@@ -39,8 +39,7 @@ func (s *MemoryStorage) Name() string {
 
 func (s *MemoryStorage) Compile() error {
 	// Delegate to active builder's CompileToMemory
-	// Note: activeSizeBuilder is in WasmClient
-	c, ok := s.Client.activeSizeBuilder.(interface {
+	c, ok := s.Client.activeBuilder().(interface {
 		CompileToMemory() ([]byte, error)
 	})
 	if !ok {
@@ -52,49 +51,153 @@ func (s *MemoryStorage) Compile() error {
 		return err
 	}
 
+	// Never swap in a binary the served runtime cannot instantiate
+	if err := s.Client.checkCompiledRuntime(content); err != nil {
+		return err
+	}
+
 	s.Mu.Lock()
 	s.WasmContent = content
 	s.LastCompile = time.Now()
 	s.Mu.Unlock()
 
+	s.Client.syncBootstrap()
+
 	return nil
 }
 
 // DiskStorage compiles WASM to disk and serves the static file.
 type DiskStorage struct {
 	Client *WasmClient
+
+	mu      sync.Mutex
+	running chan struct{} // closed when the last background build ends
 }
 
 func (s *DiskStorage) Name() string {
 	return "External"
 }
 
+// Compile builds the binary and only replaces the served file once it matches
+// the runtime of the current mode, so a mismatched build never reaches the
+// browser. With Config.Callback the build runs in the background: Compile
+// returns nil at once, and once it ends the outcome is recorded for
+// BuildStatus and passed to the Callback.
 func (s *DiskStorage) Compile() error {
+	cb := s.Client.Config.Callback
+	if cb == nil {
+		return s.compile()
+	}
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.running = done
+	s.mu.Unlock()
+	start := time.Now()
+	go func() {
+		err := s.compile()
+		s.Client.recordBuild(start, err)
+		close(done)
+		cb(err)
+	}()
+	return nil
+}
+
+// wait blocks until the background build started by the last Compile ends.
+func (s *DiskStorage) wait() {
+	s.mu.Lock()
+	done := s.running
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// background reports whether Compile runs the build in the background.
+func (s *DiskStorage) background() bool {
+	return s.Client.Config.Callback != nil
+}
+
+// compile builds into memory, validates the binary, writes it to a temporary
+// file next to the served one and renames it over it, then writes the page
+// bootstrap. A builder without CompileToMemory writes the served file itself:
+// it is validated afterwards and rolled back on mismatch.
+func (s *DiskStorage) compile() error {
 	// Ensure directory exists
 	outDir := filepath.Join(s.Client.AppRootDir, s.Client.Config.OutputDir())
 	if err := os.MkdirAll(outDir, 0755); err != nil {
 		return err
 	}
 
-	// Use existing CompileProgram which writes to config.OutputDir
-	return s.Client.activeSizeBuilder.CompileProgram()
+	builder := s.Client.activeBuilder()
+	c, ok := builder.(interface {
+		CompileToMemory() ([]byte, error)
+	})
+	if !ok {
+		// Keep the current binary so a mismatched build can be rolled back
+		finalPath := builder.FinalOutputPath()
+		previous, _ := os.ReadFile(finalPath)
+		if err := builder.CompileProgram(); err != nil {
+			return err
+		}
+		if err := s.Client.checkCompiledFile(finalPath, previous); err != nil {
+			return err
+		}
+		return s.Client.writeBootstrap()
+	}
+
+	content, err := c.CompileToMemory()
+	if err != nil {
+		return err
+	}
+
+	// Never replace the served binary with one its runtime cannot instantiate
+	if err := s.Client.checkCompiledRuntime(content); err != nil {
+		return err
+	}
+
+	finalPath := s.path()
+	tmp, err := os.CreateTemp(filepath.Dir(finalPath), filepath.Base(finalPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), finalPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return s.Client.writeBootstrap()
+}
+
+// path returns the absolute path of the served binary.
+func (s *DiskStorage) path() string {
+	return filepath.Join(s.Client.AppRootDir, s.Client.Config.OutputDir(), s.Client.OutputName+".wasm")
 }
 
 func (s *DiskStorage) RegisterRoutes(r router.Router) {
 	routePath := s.Client.wasmRoutePath()
-	result := filepath.Join(s.Client.Config.OutputDir(), s.Client.OutputName+".wasm")
-	absPath := filepath.Join(s.Client.AppRootDir, result)
-
-	r.PublicAsset(routePath, func(ctx router.Context) {
-		content, err := os.ReadFile(absPath)
-		if err != nil {
-			ctx.WriteStatus(500)
-			ctx.Write([]byte("Failed to read WASM file"))
-			return
-		}
-
-		ctx.SetHeader("Content-Type", "application/wasm")
-		ctx.Write(content)
-	})
+	r.PublicAsset(routePath, s.serveWasm)
 	s.Client.LogSuccessState("http route:", routePath)
 }
+
+// serveWasm writes the binary on disk.
+func (s *DiskStorage) serveWasm(ctx router.Context) {
+	content, err := os.ReadFile(s.path())
+	if err != nil {
+		ctx.WriteStatus(500)
+		ctx.Write([]byte("Failed to read WASM file"))
+		return
+	}
+
+	ctx.SetHeader("Content-Type", "application/wasm")
+	ctx.Write(content)
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const syscallJs = "syscall/js"
+
+// Suggested tinywasm replacements for direct syscall/js usage; the API names
+// follow the example in templates/basic_wasm_client.md.
+const (
+	domImportSuggestion   = "github.com/tinywasm/dom (elements from github.com/tinywasm/html)"
+	domMountSuggestion    = "dom.Render(id, component) / dom.Append(selector, element)"
+	domCreateSuggestion   = "tinywasm/html constructors: Div(), Button(), ... or NewElement(tag)"
+	domChildSuggestion    = "Element.Child(...) or dom.Append(selector, element)"
+	domEventSuggestion    = "Element.On(event, func(e dom.Event))"
+	domTextSuggestion     = "Element.Text(s) or Element.BindText(signal)"
+	domGlobalSuggestion   = "github.com/tinywasm/dom, or github.com/tinywasm/js for workers"
+	domFallbackSuggestion = "github.com/tinywasm/dom"
+)
+
+// jsMemberSuggestions maps the string argument of Get/Call/Set on a js.Value
+// (eg: js.Global().Get("document")) to its tinywasm/dom replacement.
+var jsMemberSuggestions = map[string]string{
+	"document":         domMountSuggestion,
+	"body":             domMountSuggestion,
+	"getElementById":   domMountSuggestion,
+	"querySelector":    domMountSuggestion,
+	"querySelectorAll": domMountSuggestion,
+	"createElement":    domCreateSuggestion,
+	"appendChild":      domChildSuggestion,
+	"append":           domChildSuggestion,
+	"addEventListener": domEventSuggestion,
+	"innerHTML":        domTextSuggestion,
+	"innerText":        domTextSuggestion,
+	"textContent":      domTextSuggestion,
+}
+
+// SyscallJsUsage is a direct syscall/js use in a project file of the wasm closure.
+type SyscallJsUsage struct {
+	Path       string `json:"path"` // relative to AppRootDir
+	Line       int    `json:"line"`
+	Expr       string `json:"expr"`       // eg: `js.Global().Get("document")`, `js.FuncOf`
+	Suggestion string `json:"suggestion"` // tinywasm/dom replacement
+}
+
+// LintSyscallJs reports the direct syscall/js imports and calls in the project
+// files compiled into the wasm binary for the current mode, each with its
+// suggested tinywasm/dom replacement. Dependencies (tinywasm/dom itself uses
+// syscall/js) are not linted.
+func (w *WasmClient) LintSyscallJs() ([]SyscallJsUsage, error) {
+	w.storageMu.RLock()
+	mode := w.compiledMode()
+	w.storageMu.RUnlock()
+
+	g, err := w.loadPackageGraph(mode)
+	if err != nil {
+		return nil, err
+	}
+	return w.lintSyscallJs(g), nil
+}
+
+// lintSyscallJs lints the main-module packages of g.
+func (w *WasmClient) lintSyscallJs(g *packageGraph) []SyscallJsUsage {
+	var usages []SyscallJsUsage
+	for _, path := range g.Order {
+		p := g.Packages[path]
+		if p.Standard || (p.Module != nil && !p.Module.Main) || p.Dir == "" || !containsString(p.Imports, syscallJs) {
+			continue
+		}
+		for _, name := range p.GoFiles {
+			full := filepath.Join(p.Dir, name)
+			usages = append(usages, lintSyscallJsFile(full, w.relPath(full))...)
+		}
+	}
+	return usages
+}
+
+// lintSyscallJsFile returns the syscall/js uses of one file: the import, DOM
+// member accesses by name and the remaining js.X calls. Only the most
+// specific finding of each line is kept.
+func lintSyscallJsFile(path, rel string) []SyscallJsUsage {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	local := ""
+	var usages []SyscallJsUsage
+	for _, spec := range file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != syscallJs {
+			continue
+		}
+		local = "js"
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+		usages = append(usages, SyscallJsUsage{
+			Path:       rel,
+			Line:       fset.Position(spec.Pos()).Line,
+			Expr:       `import "` + syscallJs + `"`,
+			Suggestion: domImportSuggestion,
+		})
+	}
+	if local == "" || local == "_" {
+		return usages
+	}
+
+	specific := map[int]bool{} // lines with a DOM member finding
+	var generic []SyscallJsUsage
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		line := fset.Position(call.Pos()).Line
+
+		if id, ok := sel.X.(*ast.Ident); ok && id.Name == local {
+			suggestion := domFallbackSuggestion
+			switch sel.Sel.Name {
+			case "FuncOf":
+				suggestion = domEventSuggestion
+			case "Global":
+				suggestion = domGlobalSuggestion
+			}
+			generic = append(generic, SyscallJsUsage{Path: rel, Line: line, Expr: local + "." + sel.Sel.Name, Suggestion: suggestion})
+			return true
+		}
+
+		switch sel.Sel.Name {
+		case "Get", "Call", "Set":
+		default:
+			return true
+		}
+		if len(call.Args) == 0 {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		member, _ := strconv.Unquote(lit.Value)
+		suggestion, ok := jsMemberSuggestions[member]
+		if !ok || specific[line] {
+			return true
+		}
+		specific[line] = true
+		usages = append(usages, SyscallJsUsage{Path: rel, Line: line, Expr: nodeString(fset, call), Suggestion: suggestion})
+		return true
+	})
+
+	for _, u := range generic {
+		if !specific[u.Line] {
+			usages = append(usages, u)
+		}
+	}
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].Line < usages[j].Line })
+	return usages
+}
+
+// nodeString prints n on a single line.
+func nodeString(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, n); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// syscallJsSummary formats usages as the lines of a report.
+func syscallJsSummary(usages []SyscallJsUsage) string {
+	var b strings.Builder
+	for _, u := range usages {
+		b.WriteString("\n⚠️ " + u.Path + ":" + strconv.Itoa(u.Line) + " " + u.Expr + " → use " + u.Suggestion)
+	}
+	return b.String()
+}
@@ -0,0 +1,154 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/js"
+	"github.com/tinywasm/router/mock"
+)
+
+func TestBootstrap_MemoryStorageServesAndFollowsRuntime(t *testing.T) {
+	cfg := client.NewConfig()
+	cfg.AssetsURLPrefix = "assets"
+	c := client.New(cfg)
+	c.SetBootstrapName("script.js")
+
+	fakeLarge, fakeMedium := newFakeCompiler(), newFakeCompiler()
+	c.SetBuilders(fakeLarge, fakeMedium, newFakeCompiler())
+	c.SetActiveBuilder(fakeLarge)
+	fakeLarge.Output = "wasm"
+	fakeMedium.Output = "wasm"
+
+	if c.BootstrapScript() != nil {
+		t.Fatal("bootstrap generated before any compilation")
+	}
+
+	r := &mock.Router{}
+	c.RegisterRoutes(r)
+
+	found := false
+	for _, route := range r.Routes() {
+		if route.Path == "/assets/script.js" && route.Public {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("bootstrap route not registered: %v", r.Routes())
+	}
+
+	if err := c.RecompileMainWasm(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &mock.Context{}
+	r.Invoke("GET", "/assets/script.js", ctx)
+	body := string(ctx.ResponseBody())
+	if !strings.Contains(body, "runtime.scheduleTimeoutEvent") {
+		t.Error("L mode bootstrap does not contain the Go runtime")
+	}
+	if !strings.Contains(body, `"/assets/client.wasm"`) {
+		t.Error("bootstrap does not fetch the served wasm route")
+	}
+
+	c.SetMode("M")
+	if err := c.RecompileMainWasm(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = &mock.Context{}
+	r.Invoke("GET", "/assets/script.js", ctx)
+	if !strings.Contains(string(ctx.ResponseBody()), "runtime.sleepTicks") {
+		t.Error("bootstrap was not regenerated for the TinyGo runtime")
+	}
+}
+
+func TestBootstrap_DiskStorageWritesNextToWasm(t *testing.T) {
+	tmp := t.TempDir()
+	c := client.New(nil)
+	c.SetAppRootDir(tmp)
+	c.SetBootstrapName("script.js")
+
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+	c.UseDiskStorage()
+
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmp, "web", "public", "script.js"))
+	if err != nil {
+		t.Fatalf("script.js not written: %v", err)
+	}
+	if !strings.Contains(string(content), "instantiateStreaming") {
+		t.Error("script.js does not contain the loader")
+	}
+}
+
+func TestBootstrap_DisabledByDefault(t *testing.T) {
+	c := client.New(nil)
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if c.BootstrapScript() != nil {
+		t.Error("bootstrap generated without SetBootstrapName")
+	}
+}
+
+func TestBootstrap_ModeChangeDuringCompile(t *testing.T) {
+	c := client.New(nil)
+	c.SetBootstrapName("script.js")
+	fakeLarge, fakeMedium := newFakeCompiler(), newFakeCompiler()
+	fakeLarge.Output, fakeMedium.Output = "wasm", "wasm"
+	c.SetBuilders(fakeLarge, fakeMedium, newFakeCompiler())
+	c.SetActiveBuilder(fakeLarge)
+
+	// Run with -race: the storage reads the mode while SetMode writes it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			c.SetMode("L")
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if err := c.Storage.Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if c.BootstrapScript() == nil {
+		t.Error("bootstrap not generated")
+	}
+}
+
+func TestBootstrap_RestoresJsRuntime(t *testing.T) {
+	js.SetRuntime(js.RuntimeTinyGo)
+	defer js.SetRuntime(js.RuntimeGo)
+
+	c := client.New(nil)
+	c.SetBootstrapName("script.js")
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(c.BootstrapScript()), "wasi_snapshot_preview1") {
+		t.Error("mode L bootstrap uses the TinyGo wasm_exec.js")
+	}
+	if !strings.Contains(js.PageBootstrap().Content, "wasi_snapshot_preview1") {
+		t.Error("the TinyGo runtime set by the caller was not restored")
+	}
+}
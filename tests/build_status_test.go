@@ -0,0 +1,180 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/json"
+	"github.com/tinywasm/mcp"
+)
+
+func TestBuildStatus(t *testing.T) {
+	c := client.New(nil)
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	status := c.BuildStatus()
+	if status.State != "none" || status.Mode != "L" || status.Compiler != "go" || status.Storage != "In-Memory" || status.BuiltAt != "" {
+		t.Fatalf("before any build: %+v", status)
+	}
+
+	fake.Output = "wasm"
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	status = c.BuildStatus()
+	if status.State != "ok" || status.Size != 4 || status.BuiltAt == "" || status.Error != "" {
+		t.Errorf("after a successful build: %+v", status)
+	}
+
+	fake.CompileErr = errors.New("# example/web\nweb/client.go:7:2: undefined: render")
+	if err := c.RecompileMainWasm(); err == nil {
+		t.Fatal("expected a compile error")
+	}
+	if c.LastBuildError() == nil {
+		t.Error("LastBuildError not set by RecompileMainWasm")
+	}
+
+	var tool mcp.Tool
+	for _, tl := range c.GetMCPTools() {
+		if tl.Name == "wasm_build_status" {
+			tool = tl
+		}
+	}
+	if tool.Execute == nil || tool.Action != 'r' {
+		t.Fatalf("wasm_build_status tool not registered as read-only: %+v", tool)
+	}
+	res, err := tool.Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: tool.Name}, Action: 'r'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := mcp.GetText(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got client.BuildStatus
+	if err := json.Decode([]byte(text), &got); err != nil {
+		t.Fatalf("decoding %s: %v", text, err)
+	}
+	if got.State != "failed" || got.Size != 0 || got.Mode != "L" {
+		t.Errorf("after a failed build: %s", text)
+	}
+	if len(got.Diagnostics) != 1 || got.Diagnostics[0].File != "web/client.go" || got.Diagnostics[0].Line != 7 ||
+		got.Diagnostics[0].Column != 2 || got.Diagnostics[0].Message != "undefined: render" {
+		t.Errorf("diagnostics = %s", text)
+	}
+}
+
+// slowCompiler blocks CompileToMemory until released and records Cancel.
+type slowCompiler struct {
+	*fakeCompiler
+	release   chan struct{}
+	cancelled bool
+}
+
+func (s *slowCompiler) CompileToMemory() ([]byte, error) {
+	<-s.release
+	return []byte("wasm"), nil
+}
+
+func (s *slowCompiler) Cancel() error {
+	s.cancelled = true
+	return nil
+}
+
+func TestCompileAndWait(t *testing.T) {
+	c := client.New(nil)
+	large, medium := newFakeCompiler(), newFakeCompiler()
+	c.SetBuilders(large, medium, newFakeCompiler())
+	c.SetMode("M")
+	large.Output, medium.Output = "large wasm", "wasm"
+
+	var tool mcp.Tool
+	for _, tl := range c.GetMCPTools() {
+		if tl.Name == "wasm_compile" {
+			tool = tl
+		}
+	}
+	if tool.Execute == nil {
+		t.Fatal("wasm_compile tool not registered")
+	}
+	res, err := tool.Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: tool.Name, Arguments: `{}`}, Action: 'u'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, _ := mcp.GetText(res)
+	var got client.BuildStatus
+	if err := json.Decode([]byte(text), &got); err != nil {
+		t.Fatalf("decoding %s: %v", text, err)
+	}
+	if got.State != "ok" || got.Mode != "M" || got.Compiler != "tinygo" || got.Size != 4 || medium.CompileCallCount != 1 {
+		t.Errorf("current mode compile: %s (medium compiled %d times)", text, medium.CompileCallCount)
+	}
+
+	// a mode override only checks that mode: nothing served or stored changes
+	large.CompileErr = errors.New("web/client.go:3:1: syntax error")
+	status, err := c.CompileAndWait("l", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Mode != "L" || status.State != "failed" || len(status.Diagnostics) != 1 || status.Diagnostics[0].Line != 3 {
+		t.Errorf("override status: %+v", status)
+	}
+	if c.Value() != "M" || c.BuildStatus().State != "ok" {
+		t.Errorf("override changed the client: mode %s, status %+v", c.Value(), c.BuildStatus())
+	}
+	if _, err := c.CompileAndWait("X", 0); err == nil {
+		t.Error("invalid mode accepted")
+	}
+
+	slow := &slowCompiler{fakeCompiler: newFakeCompiler(), release: make(chan struct{})}
+	defer close(slow.release)
+	c.SetBuilders(slow, medium, newFakeCompiler())
+	if _, err := c.CompileAndWait("L", 10*time.Millisecond); err == nil || !slow.cancelled {
+		t.Errorf("timeout: err = %v, cancelled = %v", err, slow.cancelled)
+	}
+}
+
+func TestCompileAndWait_DiskCallback(t *testing.T) {
+	cfg := client.NewConfig()
+	callbacks := make(chan error, 2)
+	cfg.Callback = func(err error) { callbacks <- err }
+	c := client.New(cfg)
+	c.SetAppRootDir(t.TempDir())
+	failing := newFakeCompiler()
+	failing.CompileErr = errors.New("web/client.go:5:1: syntax error")
+	c.SetBuilders(failing, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(failing)
+	c.UseDiskStorage()
+
+	// the build runs in the background: the status is that of its end
+	status, err := c.CompileAndWait("", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != "failed" || len(status.Diagnostics) != 1 || status.Diagnostics[0].Line != 5 {
+		t.Errorf("failed background build: %+v", status)
+	}
+	if err := <-callbacks; err == nil {
+		t.Error("Callback did not receive the compile error")
+	}
+
+	slow := &slowCompiler{fakeCompiler: newFakeCompiler(), release: make(chan struct{})}
+	c.SetBuilders(slow, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(slow)
+	time.AfterFunc(50*time.Millisecond, func() { close(slow.release) })
+	status, err = c.CompileAndWait("", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != "ok" || status.Size != 4 || status.DurationMs < 50 {
+		t.Errorf("successful background build: %+v", status)
+	}
+	if err := <-callbacks; err != nil {
+		t.Errorf("Callback: %v", err)
+	}
+}
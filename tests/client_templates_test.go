@@ -0,0 +1,197 @@
+package client_test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+// TestClientTemplatesDeclareTheirModules checks that each template's Modules
+// match the imports of its Go code, so go.mod gets exactly what it needs.
+func TestClientTemplatesDeclareTheirModules(t *testing.T) {
+	seen := map[string]bool{}
+	for _, tpl := range client.ClientTemplates() {
+		if seen[tpl.Name] {
+			t.Errorf("duplicate template name %q", tpl.Name)
+		}
+		seen[tpl.Name] = true
+
+		raw, err := os.ReadFile(filepath.Join("..", tpl.File))
+		if err != nil {
+			t.Fatalf("%s: %v", tpl.Name, err)
+		}
+		var code strings.Builder
+		inBlock := false
+		for _, line := range strings.Split(string(raw), "\n") {
+			switch {
+			case strings.HasPrefix(line, "```go"):
+				inBlock = true
+			case strings.HasPrefix(line, "```"):
+				inBlock = false
+			case inBlock:
+				code.WriteString(line + "\n")
+			}
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), tpl.File, code.String(), parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("%s: %v", tpl.Name, err)
+		}
+		imports := []string{}
+		for _, spec := range f.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			imports = append(imports, path)
+		}
+		modules := append([]string{}, tpl.Modules...)
+		sort.Strings(imports)
+		sort.Strings(modules)
+		if len(modules) == 0 {
+			modules = []string{}
+		}
+		if !reflect.DeepEqual(imports, modules) {
+			t.Errorf("%s: Modules %v, but the template imports %v", tpl.Name, modules, imports)
+		}
+		if tpl.Description == "" {
+			t.Errorf("%s: missing description", tpl.Name)
+		}
+	}
+	if !seen[client.DefaultClientTemplate] {
+		t.Errorf("default template %q not registered", client.DefaultClientTemplate)
+	}
+}
+
+func TestGenerateClientTemplate(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{})
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	var tool mcp.Tool
+	for _, tl := range c.GetMCPTools() {
+		if tl.Name == "wasm_generate_client" {
+			tool = tl
+		}
+	}
+	if !strings.Contains(tool.Description, "worker (") {
+		t.Errorf("description does not list the templates: %s", tool.Description)
+	}
+	res, err := tool.Execute(&context.Context{}, mcp.Request{
+		Params: mcp.CallToolParams{Name: tool.Name, Arguments: `{"template":"minimal","skip_ide_config":true}`},
+		Action: tool.Action,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, "✅ created web/client.go") || !strings.Contains(res.Content, `\"state\":\"ok\"`) {
+		t.Errorf("unexpected result: %s", res.Content)
+	}
+	got, err := os.ReadFile(filepath.Join(root, "web", "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `println("tinywasm: client started")`) {
+		t.Errorf("minimal template not extracted:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".vscode")); err == nil {
+		t.Error("skip_ide_config must not write .vscode")
+	}
+}
+
+const houseTemplate = "---\n" +
+	"name: house\n" +
+	"description: team starter\n" +
+	"files: main.go, styles/theme.css\n" +
+	"---\n" +
+	"# House starter\n\n" +
+	"```go\npackage main\n\nfunc main() {}\n```\n\n" +
+	"```css\nbody { margin: 0; }\n```\n"
+
+func TestUserTemplates(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"tinywasm.json":    `{"template_dir": "starters"}`,
+		"starters/team.md": "---\nname: team\n---\n```go\npackage main\n\nfunc main() {}\n```\n",
+	})
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+	c.AddTemplates(fstest.MapFS{
+		"tpl/house.md":   {Data: []byte(houseTemplate)},
+		"tpl/counter.md": {Data: []byte("---\nname: counter\ndescription: house counter\n---\n```go\npackage main\n```\n")},
+		"tpl/notes.txt":  {Data: []byte("not a template")},
+	}, "tpl")
+
+	templates, err := c.Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]client.ClientTemplate{}
+	for _, tpl := range templates {
+		byName[tpl.Name] = tpl
+	}
+	if got := byName["counter"]; got.Description != "house counter" || got.Source != "tpl" {
+		t.Errorf("user counter should replace the built-in one: %+v", got)
+	}
+	if got := byName["team"]; got.Source != "starters" {
+		t.Errorf("template_dir template not loaded: %+v", got)
+	}
+	if got := byName["house"]; !reflect.DeepEqual(got.Files, []string{"main.go", "styles/theme.css"}) {
+		t.Errorf("house files: %+v", got)
+	}
+	if got := byName["router"]; got.Source != client.BuiltinTemplateSource {
+		t.Errorf("built-in templates must stay: %+v", got)
+	}
+	if len(templates) != len(client.ClientTemplates())+2 {
+		t.Errorf("got %d templates", len(templates))
+	}
+
+	file, err := c.GenerateWasmClient("house", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != "web/client.go" {
+		t.Errorf("entry point written as %q, want the main input file", file)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "web", "client.go")); !strings.Contains(string(got), "func main()") {
+		t.Errorf("entry point: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "web", "styles", "theme.css")); string(got) != "body { margin: 0; }\n" {
+		t.Errorf("theme.css: %q", got)
+	}
+}
+
+func TestLoadClientTemplatesErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no front-matter": "# plain markdown\n",
+		"no name":         "---\ndescription: x\n---\n",
+		"bad name":        "---\nname: my template\n---\n",
+		"two go files":    "---\nname: x\nfiles: a.go, b.go\n---\n",
+		"no entry point":  "---\nname: x\nfiles: theme.css\n---\n",
+		"outside source":  "---\nname: x\nfiles: client.go, ../theme.css\n---\n",
+	} {
+		_, err := client.LoadClientTemplates(fstest.MapFS{"t/x.md": {Data: []byte(content)}}, "t")
+		if err == nil || !strings.Contains(err.Error(), "t/x.md") {
+			t.Errorf("%s: expected an error naming the file, got %v", name, err)
+		}
+	}
+	if _, err := client.LoadClientTemplates(fstest.MapFS{}, "missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
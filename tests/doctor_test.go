@@ -0,0 +1,100 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+// fakeTinyGo puts a tinygo script printing version on PATH.
+func fakeTinyGo(t *testing.T, version string) string {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho 'tinygo version " + version + " linux/amd64 (using go version go1.25.2 and LLVM version 19.1.2)'\n"
+	if err := os.WriteFile(filepath.Join(bin, "tinygo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return filepath.Join(bin, "tinygo")
+}
+
+func doctorCheck(t *testing.T, r *client.DoctorReport, name string) client.DoctorCheck {
+	t.Helper()
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in:\n%s", name, r.Summary())
+	return client.DoctorCheck{}
+}
+
+func TestDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tinygo is a shell script")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                "module example.com/app\n\ngo 1.21\n",
+		"web/client.go":         "//go:build js && wasm\n\npackage main\n\nfunc main() {}\n",
+		".vscode/settings.json": `{"gopls": {"env": {"GOOS": "js", "GOARCH": "wasm"}}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("TINYGOROOT", "")
+	fakeTinyGo(t, "0.41.1")
+
+	w := client.New(client.NewConfig())
+	w.SetAppRootDir(dir)
+	r := w.Doctor()
+	if r.Count(client.DoctorFail) != 0 || r.Count(client.DoctorWarn) != 0 {
+		t.Fatalf("healthy project reported problems:\n%s", r.Summary())
+	}
+	if c := doctorCheck(t, r, "tinygo"); !strings.HasPrefix(c.Detail, "0.41.1 at ") {
+		t.Errorf("tinygo detail = %q", c.Detail)
+	}
+	if c := doctorCheck(t, r, "go.mod"); !strings.Contains(c.Detail, "example.com/app") {
+		t.Errorf("go.mod detail = %q", c.Detail)
+	}
+
+	// a TinyGo too old for the installed Go, a leaked GOOS, a wrong
+	// TINYGOROOT and a main file excluded from the TinyGo modes
+	fakeTinyGo(t, "0.33.0")
+	t.Setenv("GOOS", "js")
+	t.Setenv("TINYGOROOT", filepath.Join(dir, "missing"))
+	if err := os.WriteFile(filepath.Join(dir, "web", "client.go"), []byte("//go:build !tinygo\n\npackage main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r = w.Doctor()
+	for name, want := range map[string]client.DoctorStatus{
+		"go/tinygo":   client.DoctorFail,
+		"GOOS/GOARCH": client.DoctorWarn,
+		"TINYGOROOT":  client.DoctorFail,
+		"main file":   client.DoctorWarn,
+	} {
+		c := doctorCheck(t, r, name)
+		if c.Status != want || c.Hint == "" {
+			t.Errorf("%s = %s (%s), hint %q; want %s with a hint", name, c.Status, c.Detail, c.Hint, want)
+		}
+	}
+	if c := doctorCheck(t, r, "GOOS/GOARCH"); !strings.Contains(c.Hint, "unset GOOS") {
+		t.Errorf("GOOS hint = %q", c.Hint)
+	}
+
+	os.Remove(filepath.Join(dir, "web", "client.go"))
+	report, err := client.RunWasmDoctor(client.WasmDoctorArgs{RootDir: dir})
+	if err == nil || doctorCheck(t, report, "main file").Status != client.DoctorFail {
+		t.Errorf("missing main file: err = %v\n%s", err, report.Summary())
+	}
+}
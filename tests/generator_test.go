@@ -47,10 +47,11 @@ func TestCreateDefaultWasmFileClientIfNotExistCreatesFile(t *testing.T) {
 	if !strings.Contains(contentStr, `"github.com/tinywasm/dom"`) {
 		t.Errorf("generated file missing tinywasm/dom import")
 	}
-	if !strings.Contains(contentStr, "Hello from tinywasm!") {
+	// Without go.mod the app name is the root directory's name
+	if !strings.Contains(contentStr, "Hello from "+filepath.Base(tmp)+"!") {
 		t.Errorf("generated file missing expected message")
 	}
-	if !strings.Contains(contentStr, `Render(`) {
+	if !strings.Contains(contentStr, `Render("app", &App{})`) {
 		t.Errorf("generated file missing Render call")
 	}
 	if !strings.Contains(contentStr, `On("click"`) {
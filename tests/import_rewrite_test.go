@@ -0,0 +1,164 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+const rewriteClientSrc = `//go:build wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func label(n int) (string, error) {
+	if n < 0 {
+		return "", errors.New("negative")
+	}
+	s := strings.ToUpper(fmt.Sprintf("item %s", strconv.Itoa(n)))
+	return s, nil
+}
+
+func main() {
+	fields := strings.Fields("a b")
+	_ = fields
+	_ = time.Now()
+	label(1)
+}
+`
+
+func TestRewriteStdlibImports(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"web/client.go": rewriteClientSrc,
+		// shared file (no wasm tag) is compiled for the server too: never touched
+		"web/shared.go": "package main\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint(1)\n",
+	})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	c.SetMainInputFile("client.go")
+
+	report, err := c.RewriteStdlibImports(true)
+	if err != nil {
+		t.Fatalf("RewriteStdlibImports: %v", err)
+	}
+
+	if len(report.Files) != 1 || report.Files[0].Path != "web/client.go" {
+		t.Fatalf("unexpected rewritten files: %+v", report.Files)
+	}
+	diff := report.Diff()
+	for _, want := range []string{
+		`-	"fmt"`,
+		`+	"github.com/tinywasm/fmt"`,
+		`fmt.Err("negative")`,
+		`strings.ToUpper(fmt.Sprintf("item %s", fmt.Convert(n).String()))`,
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	// dry-run leaves the file untouched
+	src, _ := os.ReadFile(filepath.Join(root, "web", "client.go"))
+	if string(src) != rewriteClientSrc {
+		t.Fatal("dry-run modified the file")
+	}
+
+	untranslated := map[string]bool{}
+	for _, u := range report.Untranslated {
+		untranslated[u.Expr] = true
+	}
+	// strings.Fields has no translation, so every strings use stays on the stdlib
+	if !untranslated["strings.Fields"] || !untranslated["time.Now"] {
+		t.Errorf("expected strings.Fields and time.Now untranslated, got %+v", report.Untranslated)
+	}
+	if strings.Contains(diff, `-	"strings"`) {
+		t.Error("strings import removed although strings.Fields is still used")
+	}
+	if len(report.Modules) != 1 || report.Modules[0] != "github.com/tinywasm/fmt" {
+		t.Errorf("expected tinywasm/fmt to be reported as required module, got %v", report.Modules)
+	}
+
+	// real run writes the file
+	if _, err := c.RewriteStdlibImports(false); err != nil {
+		t.Fatal(err)
+	}
+	src, _ = os.ReadFile(filepath.Join(root, "web", "client.go"))
+	if !strings.Contains(string(src), `"github.com/tinywasm/fmt"`) || strings.Contains(string(src), "errors.New") {
+		t.Errorf("file not rewritten:\n%s", src)
+	}
+	shared, _ := os.ReadFile(filepath.Join(root, "web", "shared.go"))
+	if strings.Contains(string(shared), "tinywasm") {
+		t.Error("untagged shared file was rewritten")
+	}
+}
+
+const rewriteWrapSrc = `//go:build wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrClosed = errors.New("closed")
+
+const wrapFormat = "load %s: %w"
+
+func load(name string, err error) error {
+	if err == nil {
+		return fmt.Errorf("load %s: %v", name, errors.New("empty"))
+	}
+	if name == "" {
+		return fmt.Errorf(wrapFormat, name, err)
+	}
+	return fmt.Errorf("load %s: %w", name, err)
+}
+
+func main() { load("a", ErrClosed) }
+`
+
+func TestRewriteStdlibImports_KeepsWrappingAndSentinels(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{"web/client.go": rewriteWrapSrc})
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+
+	report, err := c.RewriteStdlibImports(false)
+	if err != nil {
+		t.Fatalf("RewriteStdlibImports: %v", err)
+	}
+	src, _ := os.ReadFile(filepath.Join(root, "web", "client.go"))
+	if string(src) != rewriteWrapSrc {
+		t.Errorf("file with %%w and a sentinel error was rewritten:\n%s", src)
+	}
+
+	lines := map[int]string{}
+	for _, u := range report.Untranslated {
+		lines[u.Line] = u.Expr + " " + u.Suggestion
+	}
+	for line, want := range map[int]string{
+		10: "errors.New keep errors.New for package-level errors",
+		19: "fmt.Errorf keep fmt.Errorf: Errf does not wrap %w",
+		21: "fmt.Errorf keep fmt.Errorf: Errf does not wrap %w",
+	} {
+		if !strings.HasPrefix(lines[line], want) {
+			t.Errorf("line %d: got %q, want %q...", line, lines[line], want)
+		}
+	}
+	if _, reported := lines[17]; reported {
+		t.Errorf("fmt.Errorf without %%w reported: %q", lines[17])
+	}
+}
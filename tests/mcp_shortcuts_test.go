@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+func TestMCPToolsFollowShortcuts(t *testing.T) {
+	c := client.New(nil)
+	c.SetBuildShortcuts("D", "T", "P")
+	large := newFakeCompiler()
+	c.SetBuilders(large, newFakeCompiler(), newFakeCompiler())
+	c.SetMode("D")
+
+	tools := func() map[string]mcp.Tool {
+		m := map[string]mcp.Tool{}
+		for _, tl := range c.GetMCPTools() {
+			m[tl.Name] = tl
+		}
+		return m
+	}
+	desc := tools()["wasm_set_mode"].Description
+	for _, want := range []string{"D=LARGE (Go std, full features, not built yet)", "T=MEDIUM", "P=SMALL", "Use the shortcuts: D, T or P", "large (use D)"} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("wasm_set_mode description misses %q:\n%s", want, desc)
+		}
+	}
+	if strings.Contains(desc, "~2MB") {
+		t.Errorf("static sizes left in the description:\n%s", desc)
+	}
+
+	large.Output = strings.Repeat("x", 3<<10)
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if c.ModeSize("D") != 3<<10 {
+		t.Errorf("ModeSize(D) = %d", c.ModeSize("D"))
+	}
+	m := tools()
+	if desc := m["wasm_set_mode"].Description; !strings.Contains(desc, "D=LARGE (Go std, full features, last build 3.0 KB)") {
+		t.Errorf("size not in the description:\n%s", desc)
+	}
+
+	compile := m["wasm_compile"]
+	call := func(args string) error {
+		_, err := compile.Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: compile.Name, Arguments: args}, Action: 'u'})
+		return err
+	}
+	if err := call(`{"mode":"L"}`); err == nil {
+		t.Error("wasm_compile accepted the renamed L shortcut")
+	}
+	if err := call(`{"mode":"D"}`); err != nil {
+		t.Errorf("wasm_compile rejected the D shortcut: %v", err)
+	}
+	if _, err := m["wasm_set_mode"].Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Arguments: `{"mode":"S"}`}, Action: 'u'}); err == nil {
+		t.Error("wasm_set_mode accepted the renamed S shortcut")
+	}
+	if got := len(m["wasm_set_mode"].Args.Schema()); got != len(client.SetModeArgsModel.Fields) {
+		t.Errorf("schema has %d fields", got)
+	}
+	if extra := string(client.SetModeArgsModel.Fields[0].Permitted.Extra); extra != "LMS" {
+		t.Errorf("the generated model was modified: %q", extra)
+	}
+}
+
+func TestMCPToolsFollowProfiles(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{"web/client.go": "package main\n\nfunc main() {}\n"})
+	// the ci profile strips the debug information: a smaller binary shows its arguments applied
+	writeProjectConfig(t, root, `{"profiles": {"ci": {"mode": "L", "compiling_arguments": ["-ldflags=-s -w"]}}}`)
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	if err := c.ValidateMode("ci"); err != nil {
+		t.Fatalf("ValidateMode(ci): %v", err)
+	}
+	if err := c.ValidateMode("nope"); err == nil || !strings.Contains(err.Error(), "ci") {
+		t.Errorf("invalid mode error does not list the profile: %v", err)
+	}
+
+	m := map[string]mcp.Tool{}
+	for _, tl := range c.GetMCPTools() {
+		m[tl.Name] = tl
+	}
+	if desc := m["wasm_set_mode"].Description; !strings.Contains(desc, "tinywasm.json profile (ci)") {
+		t.Errorf("wasm_set_mode description misses the profile:\n%s", desc)
+	}
+	if extra := string(m["wasm_set_mode"].Args.Schema()[0].Permitted.Extra); !strings.Contains(extra, "c") || !strings.Contains(extra, "i") {
+		t.Errorf("mode does not permit the profile: %q", extra)
+	}
+	run := func(tool, args string) string {
+		t.Helper()
+		res, err := m[tool].Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: tool, Arguments: args}, Action: 'u'})
+		if err != nil {
+			t.Fatalf("%s %s: %v", tool, args, err)
+		}
+		return res.Content
+	}
+
+	// the profile compiles with its own arguments, L without them
+	size := func(out string) int {
+		t.Helper()
+		_, after, found := strings.Cut(out, `\"size\":`)
+		n, err := strconv.Atoi(strings.SplitN(after, ",", 2)[0])
+		if !found || err != nil {
+			t.Fatalf("no size in %s", out)
+		}
+		return n
+	}
+	if stripped, full := size(run("wasm_compile", `{"mode":"ci"}`)), size(run("wasm_compile", `{}`)); stripped >= full {
+		t.Errorf("ci binary %d bytes, L %d bytes: the profile arguments were not applied", stripped, full)
+	}
+
+	if out := run("wasm_set_mode", `{"mode":"ci"}`); !strings.Contains(out, "changed to L (profile ci)") {
+		t.Errorf("unexpected wasm_set_mode result: %s", out)
+	}
+	if status := c.BuildStatus(); status.State != "ok" {
+		t.Errorf("build after switching to ci: %s %s", status.State, status.Error)
+	}
+}
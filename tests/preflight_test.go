@@ -0,0 +1,175 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+const preflightClientSrc = `package main
+
+import "net/http"
+
+func main() { _ = http.MethodGet }
+`
+
+// newPreflightClient returns a client in mode L over a module that imports net/http,
+// with fake builders and the log lines captured.
+func newPreflightClient(t *testing.T) (*client.WasmClient, *fakeCompiler, *[]string) {
+	t.Helper()
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{"web/client.go": preflightClientSrc})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	large := newFakeCompiler()
+	c.SetBuilders(large, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(large)
+
+	var logs []string
+	c.SetLog(func(message ...any) {
+		var parts []string
+		for _, m := range message {
+			if s, ok := m.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		logs = append(logs, strings.Join(parts, " "))
+	})
+	return c, large, &logs
+}
+
+func TestChangePreflightAbortsUntilConfirmed(t *testing.T) {
+	c, large, logs := newPreflightClient(t)
+
+	c.Change("S")
+
+	if got := c.Value(); got != "L" {
+		t.Fatalf("mode = %q, want L after an unconfirmed pre-flight", got)
+	}
+	joined := strings.Join(*logs, "\n")
+	if !strings.Contains(joined, "net/http") {
+		t.Errorf("findings not logged:\n%s", joined)
+	}
+	if !strings.Contains(joined, "Select S again") {
+		t.Errorf("confirmation hint not logged:\n%s", joined)
+	}
+	if strings.Contains(joined, "Compiling mode S") || large.CompileCallCount != 0 {
+		t.Error("aborted pre-flight must not start compiling")
+	}
+}
+
+func TestChangePreflightStayLarge(t *testing.T) {
+	c, large, logs := newPreflightClient(t)
+
+	var asked string
+	c.SetOnTinyGoPreflight(func(mode string, report *client.TinyGoReport) client.PreflightDecision {
+		asked = mode
+		if report.Compatible() {
+			t.Error("report should be incompatible")
+		}
+		return client.PreflightLarge
+	})
+
+	c.Change("M")
+
+	if asked != "M" {
+		t.Errorf("callback asked for %q, want M", asked)
+	}
+	if got := c.Value(); got != "L" {
+		t.Fatalf("mode = %q, want L", got)
+	}
+	if large.CompileCallCount != 0 {
+		t.Error("staying in L must not recompile")
+	}
+	if !strings.Contains(strings.Join(*logs, "\n"), "Staying in mode L") {
+		t.Errorf("missing stay log: %v", *logs)
+	}
+}
+
+func TestSetModeToolPreflight(t *testing.T) {
+	c, _, _ := newPreflightClient(t)
+
+	tool := c.GetMCPTools()[0]
+	call := func(args string) (*mcp.Result, error) {
+		return tool.Execute(&context.Context{}, mcp.Request{
+			Params: mcp.CallToolParams{Name: tool.Name, Arguments: args},
+			Action: 'u',
+		})
+	}
+
+	res, err := call(`{"mode":"S"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content; !strings.Contains(text, "not changed") || !strings.Contains(text, "net/http") {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if got := c.Value(); got != "L" {
+		t.Errorf("mode = %q, want L", got)
+	}
+
+	if _, err := call(`{"mode":"S","on_findings":"later"}`); err == nil {
+		t.Error("expected an error for an unknown on_findings value")
+	}
+}
+
+func TestRunWasmBuildPreflight(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+	os.MkdirAll("web", 0755)
+	os.WriteFile(filepath.Join("web", "client.go"), []byte(preflightClientSrc), 0644)
+
+	var installs int
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) {
+			installs++
+			return "tinygo", nil
+		},
+		TinyGoEnv: func() []string { return nil },
+		NewClient: func(*client.Config) client.RunWasmBuildClient {
+			return &fakeRunWasmBuildClient{}
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{
+				Entry:    "web/client.go",
+				Findings: []client.TinyGoFinding{{Package: "net/http", Severity: client.TinyGoUnsupported}},
+			}, nil
+		},
+	})
+	defer restore()
+
+	if err := client.RunWasmBuild(client.WasmBuildArgs{}); client.BuildErrorClass(err) != client.WasmBuildErrPreflight || client.ExitCode(err) != 8 {
+		t.Errorf("expected the default decision to abort the build with exit 8, got %d: %v", client.ExitCode(err), err)
+	}
+	if installs != 0 {
+		t.Error("TinyGo must not be installed when the pre-flight aborts")
+	}
+
+	if err := client.RunWasmBuild(client.WasmBuildArgs{OnFindings: client.PreflightLarge}); err != nil {
+		t.Fatalf("large: %v", err)
+	}
+	if installs != 0 {
+		t.Error("falling back to the stdlib compiler must not install TinyGo")
+	}
+	script, _ := os.ReadFile(filepath.Join("web", "public", "script.js"))
+	if strings.Contains(string(script), "wasi_snapshot_preview1") {
+		t.Error("script.js should use the Go runtime after falling back to L")
+	}
+
+	if err := client.RunWasmBuild(client.WasmBuildArgs{OnFindings: client.PreflightProceed}); err != nil {
+		t.Fatalf("proceed: %v", err)
+	}
+	if installs != 1 {
+		t.Errorf("expected TinyGo install check on proceed, got %d", installs)
+	}
+}
@@ -0,0 +1,48 @@
+package client_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+func TestProgressAndSetModeOutcome(t *testing.T) {
+	c, large, _ := newPreflightClient(t)
+	var stages []string
+	c.SetOnProgress(func(p client.BuildProgress) { stages = append(stages, p.Stage+" "+p.Mode) })
+
+	tool := c.GetMCPTools()[0]
+	call := func(args string) (*mcp.Result, error) {
+		return tool.Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: tool.Name, Arguments: args}, Action: 'u'})
+	}
+
+	large.CompileErr = errors.New("web/client.go:5:1: undefined: x")
+	if _, err := call(`{"mode":"L"}`); err == nil || !strings.Contains(err.Error(), "build failed") || !strings.Contains(err.Error(), "undefined: x") {
+		t.Errorf("failed compilation reported as %v", err)
+	}
+	if got := strings.Join(stages, ", "); got != "compile L, failed L" {
+		t.Errorf("stages = %s", got)
+	}
+
+	large.CompileErr, large.Output = nil, "wasm"
+	res, err := call(`{"mode":"L"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := mcp.GetText(res); text != "Compilation mode changed to L (0.0 KB)" {
+		t.Errorf("result = %q", text)
+	}
+
+	// the pre-flight of a TinyGo mode is a step too
+	stages = nil
+	if _, err := call(`{"mode":"S"}`); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(stages, ", "); got != "preflight S" {
+		t.Errorf("stages = %s", got)
+	}
+}
@@ -0,0 +1,241 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+func writeProjectConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, client.ProjectConfigFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	pc, err := client.LoadProjectConfig(dir)
+	if pc != nil || err != nil {
+		t.Fatalf("missing file: %+v, %v", pc, err)
+	}
+
+	writeProjectConfig(t, dir, `{
+  "source_dir": "apps/shop",
+  "main_file": "main.wasm.go",
+  "output_name": "shop",
+  "mode": "prod",
+  "env": ["GOFLAGS=-mod=vendor"],
+  "max_size": "500KB"
+}`)
+	pc, err = client.LoadProjectConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig: %v", err)
+	}
+	if pc.SourceDir != "apps/shop" || pc.MainFile != "main.wasm.go" || pc.Mode != "prod" || pc.MaxSize != "500KB" {
+		t.Errorf("loaded %+v", pc)
+	}
+
+	for _, tc := range []struct{ name, content, want string }{
+		{"unknown field", `{"source": "web"}`, `unknown field "source"`},
+		{"syntax", "{\n  \"mode\": \"S\",\n}", "tinywasm.json:3:1"},
+		{"type", "{\n  \"env\": \"A=1\"\n}", "tinywasm.json:2:14: env must be a []string, not a string"},
+		{"trailing", `{} {}`, "unexpected content"},
+	} {
+		writeProjectConfig(t, dir, tc.content)
+		if _, err := client.LoadProjectConfig(dir); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	// every invalid value is reported at once
+	writeProjectConfig(t, dir, `{
+  "source_dir": "../elsewhere",
+  "main_file": "client",
+  "output_name": "dist/client.wasm",
+  "mode": "XL",
+  "env": ["GOFLAGS"],
+  "shortcuts": {"large": "L", "small": "L"},
+  "max_size": "big",
+  "profiles": {"prod": {"mode": "S"}, "Beta": {"mode": "XL", "env": ["X"]}}
+}`)
+	_, err = client.LoadProjectConfig(dir)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	// in the order of the fields, the same on every load
+	for i := 0; i < 5; i++ {
+		if _, again := client.LoadProjectConfig(dir); again == nil || again.Error() != err.Error() {
+			t.Fatalf("errors reported in another order:\n%v\n---\n%v", err, again)
+		}
+	}
+
+	last := -1
+	for _, field := range []string{"source_dir", "main_file", "output_name", "mode", "env[0]", "shortcuts.small", "max_size", "profiles.Beta", "profiles.Beta.mode", "profiles.Beta.env[0]", "profiles.prod"} {
+		i := strings.Index(err.Error(), "\n"+field+` "`)
+		if field == "source_dir" {
+			i = strings.Index(err.Error(), field+` "`)
+		}
+		if i < 0 {
+			t.Errorf("error does not report %s:\n%v", field, err)
+		} else if i < last {
+			t.Errorf("%s reported out of order:\n%v", field, err)
+		}
+		last = i
+	}
+}
+
+func TestNew_AppliesProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectConfig(t, dir, `{
+  "source_dir": "apps/shop",
+  "main_file": "main.wasm.go",
+  "output_dir": "dist",
+  "output_name": "shop",
+  "assets_url_prefix": "assets",
+  "mode": "small",
+  "shortcuts": {"large": "D", "small": "P"}
+}`)
+
+	cfg := client.NewConfig()
+	w := client.New(cfg)
+	if w.MainInputFile != "client.go" {
+		t.Fatalf("New read tinywasm.json outside AppRootDir: main = %q", w.MainInputFile)
+	}
+
+	w.SetAppRootDir(dir)
+	if err := w.ProjectConfigError(); err != nil {
+		t.Fatalf("ProjectConfigError: %v", err)
+	}
+	if got := w.MainInputFileRelativePath(); got != filepath.Join("apps", "shop", "main.wasm.go") {
+		t.Errorf("MainInputFileRelativePath = %q", got)
+	}
+	if cfg.OutputDir() != "dist" || w.OutputName != "shop" || cfg.AssetsURLPrefix != "assets" {
+		t.Errorf("output = %q/%q, prefix %q", cfg.OutputDir(), w.OutputName, cfg.AssetsURLPrefix)
+	}
+	if w.Value() != "P" || !w.TinyGoCompilerFlag {
+		t.Errorf("mode = %q (tinygo %v), want P", w.Value(), w.TinyGoCompilerFlag)
+	}
+
+	// a mode chosen earlier and stored in the Database wins over the file's
+	db := NewMockDatabase()
+	db.Set(client.StoreKeySizeMode, "D")
+	w = client.New(&client.Config{Database: db})
+	w.SetAppRootDir(dir)
+	if w.Value() != "D" {
+		t.Errorf("stored mode not restored: %q", w.Value())
+	}
+
+	writeProjectConfig(t, dir, `{"mode": "staging", "profiles": {"staging": {"mode": "M", "env": ["B=2"]}}}`)
+	cfg = client.NewConfig()
+	w = client.New(cfg)
+	w.SetAppRootDir(dir)
+	if w.Value() != "M" || strings.Join(cfg.Env, " ") != "B=2" {
+		t.Errorf("profile: mode = %q, env %v", w.Value(), cfg.Env)
+	}
+
+	writeProjectConfig(t, dir, `{"output_name": 1}`)
+	w = client.New(client.NewConfig())
+	w.SetAppRootDir(dir)
+	if w.ProjectConfigError() == nil || w.OutputName != "client" {
+		t.Errorf("invalid file: err = %v, output %q", w.ProjectConfigError(), w.OutputName)
+	}
+}
+
+func TestRunWasmBuild_ProjectConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	if err := os.MkdirAll(filepath.Join("apps", "shop"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("apps", "shop", "main.wasm.go"), []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectConfig(t, ".", `{
+  "source_dir": "apps/shop",
+  "main_file": "main.wasm.go",
+  "output_dir": "dist",
+  "output_name": "shop",
+  "assets_url_prefix": "assets",
+  "mode": "debug",
+  "env": ["GOFLAGS=-mod=vendor"]
+}`)
+
+	fake := &fakeRunWasmBuildClient{}
+	var cfg *client.Config
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) { return "tinygo", nil },
+		TinyGoEnv:             func() []string { return nil },
+		NewClient: func(c *client.Config) client.RunWasmBuildClient {
+			cfg = c
+			return fake
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{}, nil
+		},
+	})
+	defer restore()
+
+	if err := client.RunWasmBuild(client.WasmBuildArgs{Env: []string{"A=1"}}); err != nil {
+		t.Fatalf("RunWasmBuild: %v", err)
+	}
+	if fake.mode != "M" || fake.mainFile != "main.wasm.go" || fake.outputName != "shop" {
+		t.Errorf("client configured with mode=%q main=%q output=%q", fake.mode, fake.mainFile, fake.outputName)
+	}
+	if cfg.SourceDir() != filepath.Join("apps", "shop") || cfg.OutputDir() != "dist" {
+		t.Errorf("dirs = %q, %q", cfg.SourceDir(), cfg.OutputDir())
+	}
+	if got := strings.Join(cfg.Env, " "); got != "GOFLAGS=-mod=vendor A=1" {
+		t.Errorf("Env = %q", got)
+	}
+	script, err := os.ReadFile(filepath.Join("dist", "script.js"))
+	if err != nil || !strings.Contains(string(script), "/assets/shop.wasm") {
+		t.Errorf("script.js does not load /assets/shop.wasm: %v", err)
+	}
+
+	// flags win over the file
+	if err := client.RunWasmBuild(client.WasmBuildArgs{Stdlib: true, OutputName: "app"}); err != nil {
+		t.Fatalf("RunWasmBuild: %v", err)
+	}
+	if fake.mode != "L" || fake.outputName != "app" {
+		t.Errorf("flags ignored: mode=%q output=%q", fake.mode, fake.outputName)
+	}
+
+	// a custom profile sets the mode and adds its arguments and env
+	writeProjectConfig(t, ".", `{
+  "source_dir": "apps/shop",
+  "main_file": "main.wasm.go",
+  "compiling_arguments": ["-tags", "web"],
+  "env": ["GOFLAGS=-mod=vendor"],
+  "profiles": {"staging": {"mode": "small", "compiling_arguments": ["-ldflags", "-X main.env=staging"], "env": ["B=2"]}}
+}`)
+	if err := client.RunWasmBuild(client.WasmBuildArgs{Mode: "Staging", Env: []string{"A=1"}}); err != nil {
+		t.Fatalf("RunWasmBuild: %v", err)
+	}
+	if fake.mode != "S" {
+		t.Errorf("profile mode = %q, want S", fake.mode)
+	}
+	if got := strings.Join(cfg.Env, " "); got != "GOFLAGS=-mod=vendor B=2 A=1" {
+		t.Errorf("profile Env = %q", got)
+	}
+	if got := strings.Join(cfg.CompilingArguments(), " "); got != "-tags web -ldflags -X main.env=staging" {
+		t.Errorf("profile arguments = %q", got)
+	}
+
+	writeProjectConfig(t, ".", `{"mode": "XL"}`)
+	if err := client.RunWasmBuild(client.WasmBuildArgs{}); client.ExitCode(err) != 2 || !strings.Contains(err.Error(), "tinywasm.json") {
+		t.Errorf("invalid file: exit %d, err %v", client.ExitCode(err), err)
+	}
+}
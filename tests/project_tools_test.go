@@ -0,0 +1,141 @@
+package client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+func TestProjectLayoutAndStorageTools(t *testing.T) {
+	root := t.TempDir()
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	tools := map[string]mcp.Tool{}
+	for _, tl := range c.GetMCPTools() {
+		tools[tl.Name] = tl
+	}
+	call := func(name, args string) (*mcp.Result, error) {
+		tool, ok := tools[name]
+		if !ok {
+			t.Fatalf("%s tool not registered", name)
+		}
+		return tool.Execute(&context.Context{}, mcp.Request{
+			Params: mcp.CallToolParams{Name: name, Arguments: args},
+			Action: tool.Action,
+		})
+	}
+
+	layout := c.Layout()
+	if layout.MainFile != "web/client.go" || layout.MainFileExists || layout.Storage != client.StorageMemory || layout.Route != "/client.wasm" {
+		t.Fatalf("unexpected layout: %+v", layout)
+	}
+	res, err := call("wasm_project_layout", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, `\"main_file\":\"web/client.go\"`) || !strings.Contains(res.Content, "wasm_generate_client") {
+		t.Errorf("unexpected layout result: %s", res.Content)
+	}
+
+	if _, err := call("wasm_generate_client", `{"template":"unknown"}`); err == nil || !strings.Contains(err.Error(), "counter") {
+		t.Errorf("expected an unknown template error listing the templates, got %v", err)
+	}
+	writeModule(t, root, map[string]string{"web/client.go": "package main\n\nfunc main() {}\n"})
+	if _, err := call("wasm_generate_client", `{}`); err == nil || !strings.Contains(err.Error(), "never overwritten") {
+		t.Errorf("expected an existing file error, got %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "web", "client.go")); string(got) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("existing entry point modified: %q", got)
+	}
+	if !c.Layout().MainFileExists {
+		t.Error("main_file_exists should be true once the file is written")
+	}
+
+	if _, err := call("wasm_set_storage", `{"storage":"cloud"}`); err == nil {
+		t.Error("expected an error for an unknown storage")
+	}
+	res, err = call("wasm_set_storage", `{"storage":"memory"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, "already memory") || fake.CompileCallCount != 0 {
+		t.Errorf("keeping the storage must not recompile: %s", res.Content)
+	}
+
+	call("wasm_set_storage", `{"storage":"disk"}`)
+	if got := c.Layout().Storage; got != client.StorageDisk {
+		t.Fatalf("storage = %q, want disk", got)
+	}
+	res, err = call("wasm_set_storage", `{"storage":"memory"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, "changed to memory") || c.BuildStatus().State != "ok" {
+		t.Errorf("switching to memory should recompile: %s, %+v", res.Content, c.BuildStatus())
+	}
+}
+
+func TestSetStorage_RegisteredRouteFollows(t *testing.T) {
+	root := t.TempDir()
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	c.SetBootstrapName("script.js")
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	r := client.NewHTTPRouter()
+	c.RegisterRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	fake.Output = "memory build"
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/client.wasm"); got != "memory build" {
+		t.Fatalf("memory storage served %q", got)
+	}
+
+	fake.Output = "disk build"
+	if _, err := c.SetStorage(client.StorageDisk); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/client.wasm"); got != "disk build" {
+		t.Errorf("after switching to disk the route served %q", got)
+	}
+	if got := get("/script.js"); !strings.Contains(got, "client.wasm") {
+		t.Errorf("disk mode script.js = %q", got)
+	}
+
+	fake.Output = "memory again"
+	if _, err := c.SetStorage(client.StorageMemory); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/client.wasm"); got != "memory again" {
+		t.Errorf("after switching back to memory the route served %q", got)
+	}
+}
@@ -0,0 +1,203 @@
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tinywasm/client"
+)
+
+const shopTemplate = "---\n" +
+	"name: shop\n" +
+	"files: main.go, styles/app.css\n" +
+	"---\n" +
+	"```go\npackage main\n\nfunc main() { mount(\"{{mount_id}}\", \"{{app_name}}\") }\n```\n\n" +
+	"```go file=components/header.go\npackage main\n\n// header of {{module_path}}\n```\n\n" +
+	"```css\n#{{mount_id}} { margin: 0; }\n```\n\n" +
+	"```go\nfunc mount(id, title string) {}\n```\n"
+
+func TestScaffold(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{"web/styles/app.css": "/* mine */\n"})
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	fake.Output = "wasm"
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+	c.AddTemplates(fstest.MapFS{"tpl/shop.md": {Data: []byte(shopTemplate)}}, "tpl")
+
+	if got := c.DefaultTemplateVars(); got.AppName != "app" || got.MountID != "app" || got.ModulePath != "example.com/app" || got.OutputName != "client" {
+		t.Errorf("default vars: %+v", got)
+	}
+	if _, err := c.Scaffold(client.ScaffoldOptions{Template: "shop", Vars: client.TemplateVars{AppName: `say "hi"`}}); err == nil {
+		t.Error("expected an error for a quote in a variable")
+	}
+
+	result, err := c.Scaffold(client.ScaffoldOptions{
+		Template:      "shop",
+		Vars:          client.TemplateVars{AppName: "Shop", MountID: "root"},
+		SkipIDEConfig: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Created, []string{"web/client.go", "web/components/header.go"}) ||
+		!reflect.DeepEqual(result.Skipped, []string{"web/styles/app.css"}) {
+		t.Errorf("created %v, skipped %v", result.Created, result.Skipped)
+	}
+	if !strings.Contains(result.Summary(), "skipped web/styles/app.css") {
+		t.Errorf("summary: %s", result.Summary())
+	}
+
+	main, _ := os.ReadFile(filepath.Join(root, "web", "client.go"))
+	if want := "package main\n\nfunc main() { mount(\"root\", \"Shop\") }\n\nfunc mount(id, title string) {}\n"; string(main) != want {
+		t.Errorf("entry point:\n%s", main)
+	}
+	if header, _ := os.ReadFile(filepath.Join(root, "web", "components", "header.go")); !strings.Contains(string(header), "header of example.com/app") {
+		t.Errorf("header.go: %q", header)
+	}
+	if css, _ := os.ReadFile(filepath.Join(root, "web", "styles", "app.css")); string(css) != "/* mine */\n" {
+		t.Errorf("existing file overwritten: %q", css)
+	}
+	if c.BuildStatus().State != "ok" || result.BuildError != "" {
+		t.Errorf("scaffold should compile: %+v, %q", c.BuildStatus(), result.BuildError)
+	}
+
+	result, err = c.Scaffold(client.ScaffoldOptions{Template: "shop", SkipIDEConfig: true})
+	if err == nil || !strings.Contains(err.Error(), "never overwritten") || len(result.Skipped) != 3 {
+		t.Errorf("expected nothing generated, got %v, %+v", err, result)
+	}
+
+	// a generated client that does not compile is reported, not returned
+	os.Remove(filepath.Join(root, "web", "client.go"))
+	fake.CompileErr = errors.New("undefined: mount")
+	result, err = c.Scaffold(client.ScaffoldOptions{Template: "shop", SkipIDEConfig: true})
+	if err != nil || !strings.Contains(result.BuildError, "undefined: mount") {
+		t.Errorf("build error = %q, err %v", result.BuildError, err)
+	}
+}
+
+func TestPlanScaffold(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{})
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n\nrequire github.com/tinywasm/dom v0.1.0\n"), 0644)
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	if plan, err := c.PlanDefaultWasmFileClient(false); plan != nil || err != nil {
+		t.Errorf("generation disabled: expected no plan, got %+v, %v", plan, err)
+	}
+	c.SetShouldGenerateDefaultFile(func() bool { return true })
+	c.SetShouldCreateIDEConfig(func() bool { return true })
+	plan, err := c.PlanDefaultWasmFileClient(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan == nil || len(plan.Create) != 1 || plan.Create[0].Path != "web/client.go" || !strings.Contains(plan.Create[0].Content, "Hello from app!") {
+		t.Fatalf("unexpected files: %+v", plan)
+	}
+	modules := map[string]client.ModuleChange{}
+	for _, m := range plan.Modules {
+		modules[m.Module] = m
+	}
+	if m := modules["github.com/tinywasm/dom"]; m.Required != "v0.1.0" || m.Command != "go get github.com/tinywasm/dom@latest" {
+		t.Errorf("dom change: %+v", m)
+	}
+	if m := modules["github.com/tinywasm/fmt"]; m.Required != "" || m.Version != "latest" {
+		t.Errorf("fmt change: %+v", m)
+	}
+	if plan.IDEConfig == nil || plan.IDEConfig.Before != "" || !strings.Contains(plan.IDEConfig.After, `"GOOS": "js"`) {
+		t.Errorf("IDE config change: %+v", plan.IDEConfig)
+	}
+	if cs := plan.Compile; cs == nil || cs.Storage != client.StorageMemory || cs.Output != "/client.wasm" {
+		t.Errorf("compile step: %+v", cs)
+	}
+
+	diff := plan.Diff()
+	for _, want := range []string{"--- /dev/null\n+++ b/web/client.go\n@@ -0,0 +1,", "+package main\n", "+++ b/.vscode/settings.json\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	summary := plan.Summary()
+	for _, want := range []string{"create web/client.go", "go get github.com/tinywasm/fmt@latest (adds it to go.mod)", "(go.mod requires v0.1.0 now)", "compile mode"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+
+	for _, name := range []string{"web/client.go", ".vscode"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Errorf("plan wrote %s", name)
+		}
+	}
+	if fake.CompileCallCount != 0 {
+		t.Error("plan must not compile")
+	}
+
+	os.MkdirAll(filepath.Join(root, "web"), 0755)
+	os.WriteFile(filepath.Join(root, "web", "client.go"), []byte("package main\n"), 0644)
+	if plan, err := c.PlanDefaultWasmFileClient(false); plan != nil || err != nil {
+		t.Errorf("existing main file: expected no plan, got %+v, %v", plan, err)
+	}
+}
+
+func TestRunWasmInitPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := client.RunWasmInitPlan(client.WasmInitArgs{Template: "minimal", SkipIDEConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Create) != 1 || plan.Create[0].Path != "web/client.go" || plan.IDEConfig != nil {
+		t.Errorf("plan creates %+v", plan.Create)
+	}
+	if !strings.Contains(plan.Diff(), "+++ b/web/client.go") || !strings.Contains(plan.Summary(), "Template minimal would:") {
+		t.Errorf("diff:\n%s\nsummary:\n%s", plan.Diff(), plan.Summary())
+	}
+	if _, err := os.Stat(filepath.Join("web", "client.go")); err == nil {
+		t.Error("the plan wrote web/client.go")
+	}
+
+	// the IDE settings are part of the plan unless skipped
+	plan, err = client.RunWasmInitPlan(client.WasmInitArgs{Template: "minimal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.IDEConfig == nil || plan.IDEConfig.Path != ".vscode/settings.json" || !strings.Contains(plan.Summary(), "create .vscode/settings.json") {
+		t.Errorf("default plan has no IDE change: %+v", plan.IDEConfig)
+	}
+
+	// -module and -output fill their placeholders, the app name follows the module
+	plan, err = client.RunWasmInitPlan(client.WasmInitArgs{Template: "minimal", ModulePath: "example.com/shop", OutputName: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := plan.Vars; v.ModulePath != "example.com/shop" || v.OutputName != "main" || v.AppName != "shop" {
+		t.Errorf("vars = %+v", v)
+	}
+
+	if _, err := client.RunWasmInitPlan(client.WasmInitArgs{Template: "nope"}); client.ExitCode(err) != 2 {
+		t.Errorf("unknown template: exit %d, want 2", client.ExitCode(err))
+	}
+}
@@ -0,0 +1,122 @@
+package client_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/mcp"
+)
+
+const syscallJsClientSrc = `package main
+
+import "syscall/js"
+
+func main() {
+	doc := js.Global().Get("document")
+	app := doc.Call("getElementById", "app")
+	app.Set("innerHTML", "<p>hi</p>")
+	cb := js.FuncOf(func(this js.Value, args []js.Value) any { return nil })
+	app.Call("addEventListener", "click", cb)
+	_ = js.ValueOf(1)
+}
+`
+
+func TestLintSyscallJs(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{"web/client.go": syscallJsClientSrc})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+
+	usages, err := c.LintSyscallJs()
+	if err != nil {
+		t.Fatalf("LintSyscallJs: %v", err)
+	}
+
+	byLine := map[int]client.SyscallJsUsage{}
+	for _, u := range usages {
+		if u.Path != "web/client.go" {
+			t.Errorf("unexpected path %q", u.Path)
+		}
+		byLine[u.Line] = u
+	}
+	want := map[int]string{
+		3:  `import "syscall/js"`,
+		6:  `js.Global().Get("document")`,
+		7:  `doc.Call("getElementById", "app")`,
+		8:  `app.Set("innerHTML", "<p>hi</p>")`,
+		9:  `js.FuncOf`,
+		10: `app.Call("addEventListener", "click", cb)`,
+		11: `js.ValueOf`,
+	}
+	for line, expr := range want {
+		if got := byLine[line].Expr; got != expr {
+			t.Errorf("line %d: expr = %q, want %q", line, got, expr)
+		}
+	}
+	if len(usages) != len(want) {
+		t.Errorf("got %d usages, want %d: %+v", len(usages), len(want), usages)
+	}
+	if s := byLine[9].Suggestion; !strings.Contains(s, "On(") {
+		t.Errorf("js.FuncOf suggestion = %q", s)
+	}
+
+	// the compatibility report carries the lint too
+	report, err := c.AnalyzeTinyGoCompatibility()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.SyscallJs) != len(want) || !report.Compatible() {
+		t.Errorf("report.SyscallJs = %d entries, compatible = %v", len(report.SyscallJs), report.Compatible())
+	}
+	if !strings.Contains(report.Summary(), "web/client.go:9 js.FuncOf") {
+		t.Errorf("summary missing syscall/js lines:\n%s", report.Summary())
+	}
+
+	var tool mcp.Tool
+	for _, tl := range c.GetMCPTools() {
+		if tl.Name == "wasm_lint_syscall_js" {
+			tool = tl
+		}
+	}
+	if tool.Execute == nil {
+		t.Fatal("wasm_lint_syscall_js tool not registered")
+	}
+	res, err := tool.Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: tool.Name}, Action: 'r'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(res.Content, "7 direct syscall/js uses") {
+		t.Errorf("unexpected tool result: %s", res.Content)
+	}
+}
+
+func TestRunWasmLintFollowsProjectMode(t *testing.T) {
+	root := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	// syscall/js only compiled into the dev-tagged mode L
+	writeModule(t, root, map[string]string{
+		"web/client.go": "package main\n\nimport \"example.com/app/ui\"\n\nfunc main() { ui.Render() }\n",
+		"ui/ui.go":      "package ui\n\nfunc Render() {}\n",
+		"ui/debug.go":   "//go:build dev\n\npackage ui\n\nimport \"syscall/js\"\n\nvar _ = js.Global()\n",
+	})
+	if err := client.RunWasmLint(client.WasmLintArgs{}); err != nil {
+		t.Errorf("mode S linted the dev file: %v", err)
+	}
+
+	writeProjectConfig(t, root, `{"mode": "dev"}`)
+	if err := client.RunWasmLint(client.WasmLintArgs{}); err == nil || !strings.Contains(err.Error(), "syscall/js") {
+		t.Errorf("tinywasm.json mode dev: err = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+// writeModule creates a Go module under root with the given files (relative path -> content).
+func writeModule(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	files["go.mod"] = "module example.com/app\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeTinyGoCompatibility(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"web/client.go": `//go:build wasm
+
+package main
+
+import (
+	"net/http"
+
+	"example.com/app/lib"
+)
+
+func main() { _ = http.MethodGet; lib.Do() }
+`,
+		"lib/lib.go": `package lib
+
+import "strconv"
+
+func Do() string { return strconv.Itoa(1) }
+`,
+		// excluded for the wasm target: must not show up
+		"lib/lib_server.go": `//go:build !wasm
+
+package lib
+
+import "os/exec"
+
+var _ = exec.Command
+`,
+	})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+
+	report, err := c.AnalyzeTinyGoCompatibility()
+	if err != nil {
+		t.Fatalf("AnalyzeTinyGoCompatibility: %v", err)
+	}
+
+	if report.Compatible() {
+		t.Error("expected report to be incompatible because of net/http")
+	}
+
+	byPkg := map[string]client.TinyGoFinding{}
+	for _, f := range report.Findings {
+		byPkg[f.Package] = f
+	}
+
+	httpFinding, ok := byPkg["net/http"]
+	if !ok {
+		t.Fatalf("net/http not reported: %+v", report.Findings)
+	}
+	if httpFinding.Severity != client.TinyGoUnsupported {
+		t.Errorf("net/http severity = %s", httpFinding.Severity)
+	}
+
+	strconvFinding, ok := byPkg["strconv"]
+	if !ok {
+		t.Fatalf("strconv not reported: %+v", report.Findings)
+	}
+	want := "web/client.go → example.com/app/lib → strconv"
+	if got := strings.Join(strconvFinding.Chain, " → "); got != want {
+		t.Errorf("strconv chain = %q, want %q", got, want)
+	}
+
+	if _, ok := byPkg["os/exec"]; ok {
+		t.Error("os/exec comes from a !wasm file and must not be reported")
+	}
+	// crypto/tls is only reached through net/http: fixing net/http removes it
+	if _, ok := byPkg["crypto/tls"]; ok {
+		t.Error("crypto/tls should be attributed to net/http, not reported on its own")
+	}
+
+	if !strings.Contains(report.Summary(), "net/http") {
+		t.Errorf("summary does not mention net/http: %s", report.Summary())
+	}
+}
@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+func TestReportWasmFiles(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"web/client.go": `package main
+
+import "example.com/app/ui"
+
+func main() { ui.Render() }
+`,
+		"ui/ui.go": "package ui\n\nfunc Render() {}\n",
+		// forgot the !wasm tag: server code shipped to the browser
+		"ui/store.go": `package ui
+
+import "database/sql"
+
+var _ *sql.DB
+`,
+		"ui/ssr.go": `//go:build !wasm
+
+package ui
+
+import "net/http"
+
+var _ = http.MethodGet
+`,
+		"ui/debug.go": `//go:build dev
+
+package ui
+
+import "net/url"
+
+var _ = url.PathEscape
+`,
+	})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+
+	report, err := c.ReportWasmFiles()
+	if err != nil {
+		t.Fatalf("ReportWasmFiles: %v", err)
+	}
+	if report.Mode != "L" || !strings.Contains(strings.Join(report.Tags, ","), "dev") {
+		t.Errorf("expected mode L with the dev tag, got %s %v", report.Mode, report.Tags)
+	}
+
+	var ui client.WasmPackageFiles
+	for _, p := range report.Packages {
+		if p.ImportPath == "example.com/app/ui" {
+			ui = p
+		}
+	}
+	if got := strings.Join(ui.Files, ","); got != "ui/debug.go,ui/store.go,ui/ui.go" {
+		t.Errorf("ui files = %s", got)
+	}
+	if got := strings.Join(ui.Excluded, ","); got != "ui/ssr.go" {
+		t.Errorf("ui excluded = %s", got)
+	}
+
+	if len(report.Flagged) != 1 {
+		t.Fatalf("expected one flagged file, got %+v", report.Flagged)
+	}
+	f := report.Flagged[0]
+	if f.File != "ui/store.go" || f.Import != "database/sql" || f.Line != 3 {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if got := strings.Join(f.Chain, " → "); got != "web/client.go → example.com/app/ui" {
+		t.Errorf("chain = %s", got)
+	}
+
+	// net/url in a dev-only file is fine; without the dev tag the file drops out
+	c.SetMode("S")
+	report, err = c.ReportWasmFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := strings.Join(report.Files(), ","); strings.Contains(files, "ui/debug.go") {
+		t.Errorf("dev-only file compiled in mode S: %s", files)
+	}
+}
+
+func TestRunWasmFilesFollowsProjectMode(t *testing.T) {
+	root := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	// server code only compiled into the dev-tagged mode L
+	writeModule(t, root, map[string]string{
+		"web/client.go": "package main\n\nimport \"example.com/app/ui\"\n\nfunc main() { ui.Render() }\n",
+		"ui/ui.go":      "package ui\n\nfunc Render() {}\n",
+		"ui/debug.go":   "//go:build dev\n\npackage ui\n\nimport \"database/sql\"\n\nvar _ *sql.DB\n",
+	})
+	if err := client.RunWasmFiles(client.WasmFilesArgs{}); err != nil {
+		t.Errorf("mode S reported the dev file: %v", err)
+	}
+
+	writeProjectConfig(t, root, `{"mode": "local", "profiles": {"local": {"mode": "L"}}}`)
+	if err := client.RunWasmFiles(client.WasmFilesArgs{}); err == nil || !strings.Contains(err.Error(), "server-only") {
+		t.Errorf("tinywasm.json mode L: err = %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/gobuild/mock"
+	"github.com/tinywasm/js"
+)
+
+// buildWasmWithImports encodes a minimal wasm module whose import section holds
+// the given function imports (module, name pairs).
+func buildWasmWithImports(imports ...client.WasmImport) []byte {
+	uleb := func(v int) []byte {
+		var out []byte
+		for {
+			b := byte(v & 0x7f)
+			v >>= 7
+			if v != 0 {
+				out = append(out, b|0x80)
+				continue
+			}
+			return append(out, b)
+		}
+	}
+	name := func(s string) []byte {
+		return append(uleb(len(s)), s...)
+	}
+
+	body := uleb(len(imports))
+	for _, imp := range imports {
+		body = append(body, name(imp.Module)...)
+		body = append(body, name(imp.Name)...)
+		body = append(body, 0x00, 0x00) // func, type index 0
+	}
+
+	// type section with a single func() signature so type index 0 is valid
+	typeSection := []byte{0x01, 0x04, 0x01, 0x60, 0x00, 0x00}
+
+	module := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	module = append(module, typeSection...)
+	module = append(module, 0x02)
+	module = append(module, uleb(len(body))...)
+	return append(module, body...)
+}
+
+var (
+	goWasm = buildWasmWithImports(
+		client.WasmImport{Module: "gojs", Name: "runtime.wasmExit"},
+		client.WasmImport{Module: "gojs", Name: "syscall/js.valueGet"},
+	)
+	tinyGoWasm = buildWasmWithImports(
+		client.WasmImport{Module: "wasi_snapshot_preview1", Name: "fd_write"},
+		client.WasmImport{Module: "gojs", Name: "runtime.ticks"},
+		client.WasmImport{Module: "env", Name: "syscall/js.valueGet"},
+	)
+)
+
+func TestReadWasmImports(t *testing.T) {
+	imports, err := client.ReadWasmImports(tinyGoWasm)
+	if err != nil {
+		t.Fatalf("ReadWasmImports: %v", err)
+	}
+	if len(imports) != 3 {
+		t.Fatalf("expected 3 imports, got %d: %v", len(imports), imports)
+	}
+	if imports[0].String() != "wasi_snapshot_preview1.fd_write" {
+		t.Errorf("unexpected first import: %s", imports[0])
+	}
+
+	if _, err := client.ReadWasmImports([]byte("not wasm")); err == nil {
+		t.Error("expected error for non-wasm content")
+	}
+}
+
+func TestCheckWasmRuntime(t *testing.T) {
+	if err := client.CheckWasmRuntime(goWasm, "L", js.RuntimeGo); err != nil {
+		t.Errorf("Go binary rejected for Go runtime: %v", err)
+	}
+	if err := client.CheckWasmRuntime(tinyGoWasm, "S", js.RuntimeTinyGo); err != nil {
+		t.Errorf("TinyGo binary rejected for TinyGo runtime: %v", err)
+	}
+
+	err := client.CheckWasmRuntime(tinyGoWasm, "L", js.RuntimeGo)
+	var mismatch *client.WasmRuntimeMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected WasmRuntimeMismatchError, got %v", err)
+	}
+	if len(mismatch.Unexpected) != 3 {
+		t.Errorf("expected 3 unexpected imports, got %v", mismatch.Unexpected)
+	}
+
+	if err := client.CheckWasmRuntime(goWasm, "S", js.RuntimeTinyGo); err == nil {
+		t.Error("expected Go binary to be rejected for TinyGo runtime")
+	}
+}
+
+func TestMemoryStorage_RefusesMismatchedBinary(t *testing.T) {
+	c := client.New(nil)
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	fake.Output = string(goWasm)
+	if err := c.Compile(); err != nil {
+		t.Fatalf("Compile with matching binary failed: %v", err)
+	}
+
+	fake.Output = string(tinyGoWasm)
+	if err := c.Compile(); err == nil {
+		t.Fatal("expected runtime mismatch error")
+	}
+
+	mem := c.Storage.(*client.MemoryStorage)
+	if string(mem.WasmContent) != string(goWasm) {
+		t.Error("mismatched binary was swapped in")
+	}
+}
+
+// writingCompiler writes a fixed binary to its output path, like CompileProgram
+// does, and has no CompileToMemory.
+type writingCompiler struct {
+	*gobuildmock.FakeCompiler
+	path    string
+	content []byte
+}
+
+func (f *writingCompiler) CompileProgram() error {
+	return os.WriteFile(f.path, f.content, 0644)
+}
+
+func (f *writingCompiler) FinalOutputPath() string {
+	return f.path
+}
+
+func TestDiskStorage_RestoresPreviousBinaryOnMismatch(t *testing.T) {
+	tmp := t.TempDir()
+	c := client.New(nil)
+	c.SetAppRootDir(tmp)
+
+	outPath := filepath.Join(tmp, "web", "public", "client.wasm")
+	fake := &writingCompiler{FakeCompiler: &gobuildmock.FakeCompiler{}, path: outPath, content: goWasm}
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+	c.UseDiskStorage()
+
+	if err := c.Compile(); err != nil {
+		t.Fatalf("Compile with matching binary failed: %v", err)
+	}
+
+	fake.content = tinyGoWasm
+	if err := c.Compile(); err == nil {
+		t.Fatal("expected runtime mismatch error")
+	}
+
+	got, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(goWasm) {
+		t.Error("previous binary was not restored after mismatch")
+	}
+}
+
+func TestDiskStorage_ValidatesBeforeReplacingBinary(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		tmp := t.TempDir()
+		cfg := client.NewConfig()
+		results := make(chan error, 1)
+		if async {
+			cfg.Callback = func(err error) { results <- err }
+		}
+		c := client.New(cfg)
+		c.SetAppRootDir(tmp)
+		c.SetBootstrapName("script.js")
+		fake := newFakeCompiler()
+		c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+		c.SetActiveBuilder(fake)
+		c.UseDiskStorage()
+		compile := func() error {
+			err := c.Storage.Compile()
+			if async && err == nil {
+				err = <-results
+			}
+			return err
+		}
+
+		outPath := filepath.Join(tmp, "web", "public", "client.wasm")
+		fake.Output = string(goWasm)
+		if err := compile(); err != nil {
+			t.Fatalf("async=%v: compile with matching binary failed: %v", async, err)
+		}
+		if _, err := os.Stat(filepath.Join(tmp, "web", "public", "script.js")); err != nil {
+			t.Errorf("async=%v: script.js not written: %v", async, err)
+		}
+
+		fake.Output = string(tinyGoWasm)
+		if err := compile(); err == nil {
+			t.Fatalf("async=%v: expected runtime mismatch error", async)
+		}
+		if got, _ := os.ReadFile(outPath); string(got) != string(goWasm) {
+			t.Errorf("async=%v: served binary replaced by a mismatched build", async)
+		}
+		if left, _ := filepath.Glob(filepath.Join(tmp, "web", "public", "*.tmp")); len(left) > 0 {
+			t.Errorf("async=%v: temporary files left: %v", async, left)
+		}
+	}
+}
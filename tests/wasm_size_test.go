@@ -0,0 +1,170 @@
+package client_test
+
+import (
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/context"
+	"github.com/tinywasm/json"
+	"github.com/tinywasm/mcp"
+)
+
+type wasmFunc struct {
+	name string
+	body int // bytes of the body, locals and end included
+}
+
+// buildWasm assembles a binary with one func() type and funcs in order, with
+// a name section when named.
+func buildWasm(funcs []wasmFunc, named bool) []byte {
+	section := func(id byte, content []byte) []byte {
+		return append(append([]byte{id}, uleb(len(content))...), content...)
+	}
+	wasm := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	wasm = append(wasm, section(1, []byte{0x01, 0x60, 0x00, 0x00})...)
+
+	fn := uleb(len(funcs))
+	code := uleb(len(funcs))
+	names := uleb(len(funcs))
+	for i, f := range funcs {
+		fn = append(fn, 0x00)
+		body := append([]byte{0x00}, make([]byte, f.body-2)...) // no locals, nops
+		for j := 1; j < len(body); j++ {
+			body[j] = 0x01
+		}
+		body = append(body, 0x0b)
+		code = append(append(code, uleb(len(body))...), body...)
+		names = append(append(append(names, uleb(i)...), uleb(len(f.name))...), f.name...)
+	}
+	wasm = append(wasm, section(3, fn)...)
+	wasm = append(wasm, section(10, code)...)
+	if named {
+		content := append([]byte{0x04}, "name"...)
+		content = append(append(append(content, 0x01), uleb(len(names))...), names...)
+		wasm = append(wasm, section(0, content)...)
+	}
+	return wasm
+}
+
+func uleb(n int) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+var sizeTestFuncs = []wasmFunc{
+	{"main.main", 10},
+	{"github.com_tinywasm_dom.Get", 20},
+	{"github.com_tinywasm_dom._*Element_.Set", 20},
+	{"(*fmt.pp).doPrintf", 40},
+	{"runtime.alloc", 5},
+	{"type_.eq.main.T", 3},
+}
+
+func TestInspectWasm(t *testing.T) {
+	wasm := buildWasm(sizeTestFuncs, true)
+	r, err := client.InspectWasm(wasm, "github.com/tinywasm/dom", "fmt", "runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Size != int64(len(wasm)) || len(r.Sections) != 4 || r.Sections[0].Size < r.Sections[1].Size || r.Note != "" {
+		t.Errorf("report: %+v", r)
+	}
+	// each body counts its size prefix too
+	want := []client.WasmPackageSize{
+		{Package: "github.com/tinywasm/dom", Size: 42, Functions: 2},
+		{Package: "fmt", Size: 41, Functions: 1},
+		{Package: "main", Size: 11, Functions: 1},
+		{Package: "runtime", Size: 6, Functions: 1},
+		{Package: "(other)", Size: 4, Functions: 1},
+	}
+	if len(r.Packages) != len(want) {
+		t.Fatalf("packages = %+v", r.Packages)
+	}
+	for i, p := range want {
+		if r.Packages[i] != p {
+			t.Errorf("packages[%d] = %+v, want %+v", i, r.Packages[i], p)
+		}
+	}
+
+	top := r.Top(2)
+	if len(top.Packages) != 3 || top.Packages[2].Package != "(3 more packages)" || top.Packages[2].Size != 21 || len(r.Packages) != 5 {
+		t.Errorf("Top(2) = %+v", top.Packages)
+	}
+	if s := top.Summary(); !strings.Contains(s, "github.com/tinywasm/dom 0.0 KB") || !strings.Contains(s, "code by package:") {
+		t.Errorf("summary:\n%s", s)
+	}
+
+	r, err = client.InspectWasm(buildWasm(sizeTestFuncs, false))
+	if err != nil || len(r.Packages) != 0 || !strings.Contains(r.Note, "no name section") {
+		t.Errorf("stripped binary: %+v, %v", r, err)
+	}
+	if _, err := client.InspectWasm([]byte("not wasm")); err == nil {
+		t.Error("expected an error for a non-wasm input")
+	}
+}
+
+func TestMCPSizeAndTinyGoTools(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"web/client.go": "package main\n\nimport \"net/http\"\n\nfunc main() { _ = http.MethodGet }\n",
+	})
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	fake := newFakeCompiler()
+	c.SetBuilders(fake, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(fake)
+
+	tools := map[string]mcp.Tool{}
+	for _, tl := range c.GetMCPTools() {
+		tools[tl.Name] = tl
+	}
+	call := func(name, args string) []string {
+		t.Helper()
+		res, err := tools[name].Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Name: name, Arguments: args}, Action: 'r'})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var list mcp.TextContentList
+		if err := json.Decode([]byte(res.Content), &list); err != nil || len(list) != 2 {
+			t.Fatalf("%s: content %s: %v", name, res.Content, err)
+		}
+		return []string{list[0].Text, list[1].Text}
+	}
+
+	if _, err := tools["wasm_size_report"].Execute(&context.Context{}, mcp.Request{Params: mcp.CallToolParams{Arguments: `{}`}, Action: 'r'}); err == nil {
+		t.Error("size report before any build")
+	}
+	fake.Output = string(buildWasm(sizeTestFuncs, true))
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	out := call("wasm_size_report", `{}`)
+	var size client.WasmSizeReport
+	if err := stdjson.Unmarshal([]byte(out[0]), &size); err != nil {
+		t.Fatal(err)
+	}
+	if size.Mode != "L" || size.Size != int64(len(fake.Output)) || len(size.Packages) == 0 || !strings.HasPrefix(out[1], "mode L: ") {
+		t.Errorf("wasm_size_report: %s\n%s", out[0], out[1])
+	}
+
+	out = call("wasm_tinygo_check", `{}`)
+	var check struct {
+		Compatible bool                   `json:"compatible"`
+		Findings   []client.TinyGoFinding `json:"findings"`
+	}
+	if err := stdjson.Unmarshal([]byte(out[0]), &check); err != nil {
+		t.Fatal(err)
+	}
+	if check.Compatible || len(check.Findings) != 1 || check.Findings[0].Package != "net/http" || !strings.Contains(out[1], "❌ net/http") {
+		t.Errorf("wasm_tinygo_check: %s\n%s", out[0], out[1])
+	}
+}
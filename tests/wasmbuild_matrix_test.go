@@ -0,0 +1,228 @@
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+// fakeOutputClient writes a binary of size bytes named after SetOutputName:
+// module, when set, padded with empty sections.
+type fakeOutputClient struct {
+	fakeRunWasmBuildClient
+	dir    string
+	size   int
+	module []byte
+}
+
+func (f *fakeOutputClient) Compile() error {
+	content := make([]byte, f.size)
+	copy(content, f.module)
+	return os.WriteFile(filepath.Join(f.dir, f.outputName+".wasm"), content, 0644)
+}
+
+func TestRunWasmMatrix(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	if err := os.MkdirAll("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("web", "client.go"), []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := map[string]int{"L": 4096, "M": 2048, "S": 1024}
+	var installs int
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) {
+			installs++
+			return "tinygo", nil
+		},
+		TinyGoEnv:             func() []string { return nil },
+		NewClient: func(cfg *client.Config) client.RunWasmBuildClient {
+			return &modeSizedClient{fakeOutputClient: fakeOutputClient{dir: cfg.OutputDir()}, sizes: sizes}
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{}, nil
+		},
+	})
+	defer restore()
+
+	report, err := client.RunWasmMatrix(client.WasmMatrixArgs{WasmBuildArgs: client.WasmBuildArgs{Log: func(...any) {}}})
+	if err != nil {
+		t.Fatalf("RunWasmMatrix: %v", err)
+	}
+	if len(report.Entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(report.Entries))
+	}
+	for i, want := range []struct {
+		mode, compiler, file string
+		size                 int64
+	}{
+		{"L", "go", "web/public/client-large.wasm", 4096},
+		{"M", "tinygo", "web/public/client-medium.wasm", 2048},
+		{"S", "tinygo", "web/public/client-small.wasm", 1024},
+	} {
+		e := report.Entries[i]
+		if e.Mode != want.mode || e.Result.Compiler != want.compiler || e.Result.Status != "ok" {
+			t.Errorf("entry %d = %s %s %s", i, e.Mode, e.Result.Compiler, e.Result.Status)
+			continue
+		}
+		if a := e.Result.Artifacts[0]; a.Path != want.file || a.Size != want.size {
+			t.Errorf("%s artifact = %s (%d bytes)", e.Mode, a.Path, a.Size)
+		}
+		if want := map[string]int{"L": 2, "M": 3, "S": 3}[e.Mode]; e.Imports != want {
+			t.Errorf("%s imports = %d, want %d", e.Mode, e.Imports, want)
+		}
+	}
+	for name, tinygo := range map[string]bool{"script-large.js": false, "script-medium.js": true, "script-small.js": true} {
+		script, err := os.ReadFile(filepath.Join("web", "public", name))
+		if err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		} else if strings.Contains(string(script), "wasi_snapshot_preview1") != tinygo {
+			t.Errorf("%s has the wrong wasm_exec runtime (tinygo %v)", name, !tinygo)
+		}
+	}
+	if installs != 1 {
+		t.Errorf("TinyGo install checked %d times, want once", installs)
+	}
+
+	table := report.Table()
+	for _, want := range []string{"MODE", "client-large.wasm", "4.0 KB", "client-small.wasm"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table lacks %q:\n%s", want, table)
+		}
+	}
+
+	// a missing TinyGo fails M and S but still builds L
+	restore2 := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) { return "", errors.New("offline") },
+	})
+	defer restore2()
+	report, err = client.RunWasmMatrix(client.WasmMatrixArgs{
+		WasmBuildArgs: client.WasmBuildArgs{Log: func(...any) {}},
+		Modes:         []string{"dev", "prod"},
+	})
+	if client.ExitCode(err) != 3 || len(report.Entries) != 2 {
+		t.Fatalf("err = %v (exit %d), entries %d", err, client.ExitCode(err), len(report.Entries))
+	}
+	if report.Entries[0].Result.Status != "ok" || report.Entries[1].Result.Class != client.WasmBuildErrToolchain {
+		t.Errorf("L = %s, S = %s", report.Entries[0].Result.Status, report.Entries[1].Result.Class)
+	}
+	if !strings.Contains(report.Table(), "✗ toolchain") {
+		t.Errorf("table does not flag the failed mode:\n%s", report.Table())
+	}
+
+	if _, err := client.RunWasmMatrix(client.WasmMatrixArgs{Modes: []string{"XL"}}); client.ExitCode(err) != 2 {
+		t.Errorf("invalid mode: exit %d, want 2", client.ExitCode(err))
+	}
+}
+
+// modeSizedClient writes a binary whose size depends on the mode.
+type modeSizedClient struct {
+	fakeOutputClient
+	sizes map[string]int
+}
+
+func (f *modeSizedClient) Compile() error {
+	f.size = f.sizes[f.mode]
+	f.module = goWasm
+	if f.mode != "L" {
+		f.module = tinyGoWasm
+	}
+	return f.fakeOutputClient.Compile()
+}
+
+// envRecordingClient records the compiler env of each output name.
+type envRecordingClient struct {
+	fakeOutputClient
+	cfg  *client.Config
+	envs map[string]string
+	mu   *sync.Mutex
+}
+
+func (f *envRecordingClient) Compile() error {
+	f.mu.Lock()
+	f.envs[f.outputName] = strings.Join(f.cfg.Env, " ")
+	f.mu.Unlock()
+	f.size = 512
+	return f.fakeOutputClient.Compile()
+}
+
+func TestRunWasmMatrix_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	writeModule(t, tmpDir, map[string]string{"web/client.go": "package main\nfunc main() {}"})
+	writeProjectConfig(t, tmpDir, `{"mode": "staging", "profiles": {"staging": {"mode": "S", "env": ["STAGE=1"]}}}`)
+
+	envs := map[string]string{}
+	var mu sync.Mutex
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) { return "tinygo", nil },
+		TinyGoEnv:             func() []string { return nil },
+		NewClient: func(cfg *client.Config) client.RunWasmBuildClient {
+			return &envRecordingClient{fakeOutputClient: fakeOutputClient{dir: cfg.OutputDir()}, cfg: cfg, envs: envs, mu: &mu}
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{}, nil
+		},
+	})
+	defer restore()
+
+	// the default set adds the configured profiles to L, M and S
+	report, err := client.RunWasmMatrix(client.WasmMatrixArgs{WasmBuildArgs: client.WasmBuildArgs{Log: func(...any) {}}})
+	if err != nil {
+		t.Fatalf("RunWasmMatrix: %v", err)
+	}
+	if len(report.Entries) != 4 {
+		t.Fatalf("entries = %d, want 4", len(report.Entries))
+	}
+	if e := report.Entries[3]; e.Mode != "S" || e.Profile != "staging" || e.Result.Artifacts[0].Path != "web/public/client-staging.wasm" {
+		t.Errorf("staging entry = %+v", e)
+	}
+	if envs["client-staging"] != "STAGE=1" {
+		t.Errorf("staging env = %q", envs["client-staging"])
+	}
+	// the mode of tinywasm.json names the profile, but only its own build gets its env
+	for _, name := range []string{"client-large", "client-medium", "client-small"} {
+		if env, ok := envs[name]; !ok || env != "" {
+			t.Errorf("%s env = %q (built %v), want none", name, env, ok)
+		}
+	}
+	if !strings.Contains(report.Table(), "staging (S)") {
+		t.Errorf("table lacks the profile:\n%s", report.Table())
+	}
+
+	// a profile can be requested by name
+	report, err = client.RunWasmMatrix(client.WasmMatrixArgs{
+		WasmBuildArgs: client.WasmBuildArgs{Log: func(...any) {}},
+		Modes:         []string{"Staging", "S"},
+	})
+	if err != nil || len(report.Entries) != 2 || report.Entries[0].Profile != "staging" || report.Entries[1].Profile != "" {
+		t.Fatalf("entries %+v, err %v", report.Entries, err)
+	}
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+// fakeCompileClient runs compile instead of a real compiler.
+type fakeCompileClient struct {
+	fakeRunWasmBuildClient
+	compile func() error
+}
+
+func (f *fakeCompileClient) Compile() error { return f.compile() }
+
+func TestBuildWasm_ResultAndExitCodes(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	quiet := func(...any) {}
+	if _, err := client.BuildWasm(client.WasmBuildArgs{Stdlib: true, Log: quiet}); client.ExitCode(err) != 6 {
+		t.Errorf("missing input: class %q exit %d, want io/6", client.BuildErrorClass(err), client.ExitCode(err))
+	}
+
+	if err := os.MkdirAll("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("web", "client.go"), []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.BuildWasm(client.WasmBuildArgs{Mode: "XL", Log: quiet}); client.ExitCode(err) != 2 {
+		t.Errorf("invalid mode: exit %d, want 2", client.ExitCode(err))
+	}
+
+	var compileErr error
+	wasm := make([]byte, 2048)
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) { return "", errors.New("download failed") },
+		NewClient: func(*client.Config) client.RunWasmBuildClient {
+			return &fakeCompileClient{compile: func() error {
+				if compileErr != nil {
+					return compileErr
+				}
+				return os.WriteFile(filepath.Join("web", "public", "client.wasm"), wasm, 0644)
+			}}
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{}, nil
+		},
+	})
+	defer restore()
+
+	if _, err := client.BuildWasm(client.WasmBuildArgs{Log: quiet}); client.ExitCode(err) != 3 {
+		t.Errorf("TinyGo install failure: class %q, want toolchain", client.BuildErrorClass(err))
+	}
+
+	result, err := client.BuildWasm(client.WasmBuildArgs{Stdlib: true, Log: quiet})
+	if err != nil {
+		t.Fatalf("BuildWasm: %v", err)
+	}
+	if result.Status != "ok" || result.Mode != "L" || result.Compiler != "go" || len(result.Artifacts) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if a := result.Artifacts[0]; a.Path != "web/public/client.wasm" || a.Size != 2048 || a.GzipSize == 0 || len(a.SHA256) != 64 {
+		t.Errorf("wasm artifact = %+v", a)
+	}
+
+	result, err = client.BuildWasm(client.WasmBuildArgs{Stdlib: true, MaxSize: 1024, Log: quiet})
+	if client.ExitCode(err) != 5 || result.Class != client.WasmBuildErrBudget || result.Status != "error" {
+		t.Errorf("over budget: exit %d, result %+v", client.ExitCode(err), result)
+	}
+
+	compileErr = errors.New("compileSync build failed: exit status 1 # p/web\nweb/client.go:2:15: undefined: x\n")
+	result, err = client.BuildWasm(client.WasmBuildArgs{Stdlib: true, Log: quiet})
+	if client.ExitCode(err) != 4 {
+		t.Fatalf("compile error: exit %d, want 4", client.ExitCode(err))
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("diagnostics = %+v", result.Diagnostics)
+	}
+	if d := result.Diagnostics[0]; d.File != "web/client.go" || d.Line != 2 || d.Column != 15 || d.Message != "undefined: x" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+
+	compileErr = fmt.Errorf("compilation failed: %w", &exec.Error{Name: "go", Err: exec.ErrNotFound})
+	if _, err := client.BuildWasm(client.WasmBuildArgs{Stdlib: true, Log: quiet}); client.ExitCode(err) != 3 {
+		t.Errorf("missing compiler: class %q, want toolchain", client.BuildErrorClass(err))
+	}
+	compileErr = errors.New(`web/client.go:3:2: "executable file not found" is not a Go statement`)
+	if _, err := client.BuildWasm(client.WasmBuildArgs{Stdlib: true, Log: quiet}); client.ExitCode(err) != 4 {
+		t.Errorf("compile error quoting the message: class %q, want compile", client.BuildErrorClass(err))
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "500KB": 500 * 1024, "1.5MB": 1536 * 1024, "2m": 2 << 20} {
+		if got, err := client.ParseByteSize(in); err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := client.ParseByteSize("big"); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+}
@@ -0,0 +1,229 @@
+package client_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tinywasm/client"
+	"github.com/tinywasm/model"
+	"github.com/tinywasm/router"
+)
+
+// fakeServeClient serves a fixed binary and reports one successful rebuild per Watch.
+type fakeServeClient struct {
+	fakeRunWasmBuildClient
+	memory    bool
+	bootstrap string
+}
+
+func (f *fakeServeClient) UseMemoryStorage()            { f.memory = true }
+func (f *fakeServeClient) SetBootstrapName(name string) { f.bootstrap = name }
+
+func (f *fakeServeClient) RegisterRoutes(r router.Router) {
+	r.PublicAsset("/"+f.outputName+".wasm", func(c router.Context) {
+		c.SetHeader("Content-Type", "application/wasm")
+		c.Write([]byte("\x00asm"))
+	})
+}
+
+func (f *fakeServeClient) Watch(stop <-chan struct{}, onResult func(client.WatchResult)) error {
+	onResult(client.WatchResult{Trigger: "web/client.go", Event: "write", Mode: f.mode})
+	<-stop
+	return nil
+}
+
+func TestWasmDevServer(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	if err := os.MkdirAll(filepath.Join("web", "public"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("web", "client.go"), []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("web", "public", "style.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeServeClient{}
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		NewClient: func(*client.Config) client.RunWasmBuildClient { return fake },
+	})
+	defer restore()
+
+	s, err := client.NewWasmDevServer(client.WasmBuildArgs{Stdlib: true})
+	if err != nil {
+		t.Fatalf("NewWasmDevServer: %v", err)
+	}
+	if !fake.memory || fake.bootstrap != "script.js" || fake.mode != "L" || fake.compileCalls != 1 {
+		t.Errorf("client configured with memory=%v bootstrap=%q mode=%q compiles=%d", fake.memory, fake.bootstrap, fake.mode, fake.compileCalls)
+	}
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := get("/")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `<script src="/script.js">`) {
+		t.Errorf("GET / = %d %q", resp.StatusCode, body)
+	}
+	if !strings.Contains(body, `EventSource("/_wasmbuild/reload")`) || strings.Index(body, "EventSource") > strings.Index(body, "</body>") {
+		t.Errorf("reload script not injected before </body>: %q", body)
+	}
+
+	resp, body = get("/style.css")
+	if body != "body{}" || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/css") {
+		t.Errorf("GET /style.css = %q (%s)", body, resp.Header.Get("Content-Type"))
+	}
+	if _, body = get("/client.wasm"); body != "\x00asm" {
+		t.Errorf("GET /client.wasm = %q, want the in-memory binary", body)
+	}
+	if resp, _ = get("/../client.go"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /../client.go = %d, want 404", resp.StatusCode)
+	}
+	if resp, _ = get("/missing.js"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing.js = %d, want 404", resp.StatusCode)
+	}
+
+	// a successful rebuild reloads the connected pages
+	stream, err := http.Get(srv.URL + "/_wasmbuild/reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	lines := bufio.NewReader(stream.Body)
+	if line, _ := lines.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("stream opened with %q", line)
+	}
+
+	stop := make(chan struct{})
+	watched := make(chan error, 1)
+	go func() { watched <- s.Watch(stop) }()
+
+	got := make(chan string, 1)
+	go func() {
+		for {
+			line, err := lines.ReadString('\n')
+			if err != nil || strings.HasPrefix(line, "event: reload") {
+				got <- line
+				return
+			}
+		}
+	}()
+	select {
+	case line := <-got:
+		if !strings.HasPrefix(line, "event: reload") {
+			t.Errorf("stream ended without a reload event: %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload event after a successful rebuild")
+	}
+	close(stop)
+	if err := <-watched; err != nil {
+		t.Errorf("Watch: %v", err)
+	}
+}
+
+func TestWasmDevServer_AssetsURLPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	if err := os.MkdirAll(filepath.Join("web", "public"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("web", "client.go"), []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectConfig(t, ".", `{"assets_url_prefix": "/static/"}`)
+
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		NewClient: func(*client.Config) client.RunWasmBuildClient { return &fakeServeClient{} },
+	})
+	defer restore()
+
+	s, err := client.NewWasmDevServer(client.WasmBuildArgs{Stdlib: true})
+	if err != nil {
+		t.Fatalf("NewWasmDevServer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `<script src="/static/script.js">`) {
+		t.Errorf("GET / does not load the prefixed script.js: %q", body)
+	}
+}
+
+func TestHTTPRouterPermissions(t *testing.T) {
+	r := client.NewHTTPRouter()
+	ok := func(c router.Context) { c.Write([]byte("ok:" + c.Path())) }
+	r.Get("/private", ok)
+	r.Get("/public", ok).Public()
+	r.Get("/orders/:id", ok).Requires("orders", 'r')
+	r.Use(func(next router.HandlerFunc) router.HandlerFunc {
+		return func(c router.Context) {
+			c.SetUserID(c.GetHeader("X-User"))
+			next(c)
+		}
+	})
+
+	status := func(path, user string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if got := status("/private", ""); got != http.StatusForbidden {
+		t.Errorf("unannotated route = %d, want 403", got)
+	}
+	if got := status("/public", ""); got != http.StatusOK {
+		t.Errorf("public route = %d, want 200", got)
+	}
+	if got := status("/orders/7", "ana"); got != http.StatusForbidden {
+		t.Errorf("Requires route without Authorize = %d, want 403", got)
+	}
+
+	r.Authorize = func(userID string, _ model.Resource, _ model.Action) bool { return userID == "ana" }
+	if got := status("/orders/7", "ana"); got != http.StatusOK {
+		t.Errorf("authorized user = %d, want 200", got)
+	}
+	if got := status("/orders/7", "bob"); got != http.StatusForbidden {
+		t.Errorf("unauthorized user = %d, want 403", got)
+	}
+
+	if n := len(r.Routes()); n != 3 {
+		t.Errorf("Routes() = %d entries, want 3", n)
+	}
+}
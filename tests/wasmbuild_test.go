@@ -1,6 +1,7 @@
 package client_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -245,9 +246,16 @@ func TestRunWasmBuild_IncludesTinyGoEnv(t *testing.T) {
 
 type fakeRunWasmBuildClient struct {
 	compileCalls int
+	mainFile     string
+	outputName   string
+	mode         string
 }
 
-func (f *fakeRunWasmBuildClient) SetMode(string) {}
+func (f *fakeRunWasmBuildClient) SetMainInputFile(file string) { f.mainFile = file }
+
+func (f *fakeRunWasmBuildClient) SetOutputName(name string) { f.outputName = name }
+
+func (f *fakeRunWasmBuildClient) SetMode(mode string) { f.mode = mode }
 
 func (f *fakeRunWasmBuildClient) UseDiskStorage() {}
 
@@ -259,3 +267,122 @@ func (f *fakeRunWasmBuildClient) Compile() error {
 }
 
 func (f *fakeRunWasmBuildClient) LogSuccessState(...any) {}
+
+func TestRunWasmBuild_CustomLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	mainPath := filepath.Join("apps", "shop", "main.wasm.go")
+	if err := os.MkdirAll(filepath.Dir(mainPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mainPath, []byte("package main\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeRunWasmBuildClient{}
+	var cfg *client.Config
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		EnsureTinyGoInstalled: func() (string, error) { return "tinygo", nil },
+		TinyGoEnv:             func() []string { return []string{"TINYGOROOT=/tinygo"} },
+		NewClient: func(c *client.Config) client.RunWasmBuildClient {
+			cfg = c
+			return fake
+		},
+		AnalyzeTinyGo: func(*client.Config, string) (*client.TinyGoReport, error) {
+			return &client.TinyGoReport{}, nil
+		},
+	})
+	defer restore()
+
+	err = client.RunWasmBuild(client.WasmBuildArgs{
+		Mode:       "debug",
+		SourceDir:  filepath.Join("apps", "shop"),
+		MainFile:   "main.wasm.go",
+		OutputDir:  filepath.Join("dist", "shop"),
+		OutputName: "shop",
+		BuildArgs:  []string{"-ldflags", "-X main.version=1"},
+		Env:        []string{"GOFLAGS=-mod=mod"},
+	})
+	if err != nil {
+		t.Fatalf("RunWasmBuild: %v", err)
+	}
+
+	if fake.mode != "M" || fake.mainFile != "main.wasm.go" || fake.outputName != "shop" {
+		t.Errorf("client configured with mode=%q main=%q output=%q", fake.mode, fake.mainFile, fake.outputName)
+	}
+	if got := cfg.SourceDir(); got != filepath.Join("apps", "shop") {
+		t.Errorf("SourceDir = %q", got)
+	}
+	if got := cfg.OutputDir(); got != filepath.Join("dist", "shop") {
+		t.Errorf("OutputDir = %q", got)
+	}
+	if got := strings.Join(cfg.CompilingArguments(), " "); got != "-ldflags -X main.version=1" {
+		t.Errorf("CompilingArguments = %q", got)
+	}
+	if got := strings.Join(cfg.Env, " "); got != "TINYGOROOT=/tinygo GOFLAGS=-mod=mod" {
+		t.Errorf("Env = %q", got)
+	}
+
+	script, err := os.ReadFile(filepath.Join("dist", "shop", "script.js"))
+	if err != nil {
+		t.Fatalf("script.js not written to the output dir: %v", err)
+	}
+	if !strings.Contains(string(script), "/shop.wasm") {
+		t.Error("script.js does not load shop.wasm")
+	}
+
+	if err := client.RunWasmBuild(client.WasmBuildArgs{Mode: "X", SourceDir: filepath.Join("apps", "shop"), MainFile: "main.wasm.go"}); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+	if err := client.RunWasmBuild(client.WasmBuildArgs{Stdlib: true, Mode: "S"}); err == nil {
+		t.Error("expected -stdlib to conflict with mode S")
+	}
+}
+
+func TestRunWasmBuild_WatchFollowsProjectConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	writeModule(t, tmpDir, map[string]string{"src/app.go": "package main\nfunc main() {}"})
+	writeProjectConfig(t, tmpDir, `{"source_dir": "src", "main_file": "app.go"}`)
+
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		NewClient: func(*client.Config) client.RunWasmBuildClient { return &fakeServeClient{} },
+	})
+	defer restore()
+
+	stop := make(chan struct{})
+	close(stop)
+	var logs []string
+	err = client.RunWasmBuild(client.WasmBuildArgs{
+		Mode:      "L",
+		Watch:     true,
+		WatchStop: stop,
+		Log:       func(message ...any) { logs = append(logs, fmt.Sprint(message...)) },
+	})
+	if err != nil {
+		t.Fatalf("RunWasmBuild: %v", err)
+	}
+	want := "watching " + filepath.Join("src", "app.go")
+	for _, l := range logs {
+		if strings.HasPrefix(l, want) {
+			return
+		}
+	}
+	t.Errorf("no %q banner in %q", want, logs)
+}
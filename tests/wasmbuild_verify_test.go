@@ -0,0 +1,139 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinywasm/client"
+)
+
+// fakeVerifyClient writes a wasm binary whose main.main body is body, into
+// the project copy set by SetAppRootDir.
+type fakeVerifyClient struct {
+	fakeRunWasmBuildClient
+	cfg  *client.Config
+	root string
+	body func(root string) byte
+}
+
+func (f *fakeVerifyClient) SetAppRootDir(dir string) { f.root = dir }
+
+func (f *fakeVerifyClient) Compile() error {
+	wasm := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	// import section: env.f, a function
+	wasm = append(wasm, 0x02, 0x09, 0x01, 0x03, 'e', 'n', 'v', 0x01, 'f', 0x00, 0x00)
+	// code section: two bodies, the second one depends on the copy
+	wasm = append(wasm, 0x0a, 0x08, 0x02, 0x02, 0x00, 0x0b, 0x03, 0x00, f.body(f.root), 0x0b)
+	// name section: function 2 is main.main
+	wasm = append(wasm, 0x00, 0x13, 0x04, 'n', 'a', 'm', 'e', 0x01, 0x0c, 0x01, 0x02, 0x09)
+	wasm = append(wasm, "main.main"...)
+	return os.WriteFile(filepath.Join(f.root, f.cfg.OutputDir(), f.outputName+".wasm"), wasm, 0644)
+}
+
+func TestRunWasmVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldWd)
+
+	for name, content := range map[string]string{
+		"go.mod":                  "module example.com/app\n\ngo 1.21\n\ntoolchain go1.25.2\n",
+		"web/client.go":           "package main\nfunc main() {}",
+		"web/public/client.wasm":  "stale output, not copied",
+		".git/HEAD":               "ref: refs/heads/main",
+		"web/components/cart.go":  "package components",
+		"node_modules/x/index.js": "",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfgs []*client.Config
+	var roots []string
+	body := func(string) byte { return 0x01 }
+	restore := client.SetRunWasmBuildHooks(client.RunWasmBuildHooks{
+		NewClient: func(cfg *client.Config) client.RunWasmBuildClient {
+			cfgs = append(cfgs, cfg)
+			return &fakeVerifyClient{cfg: cfg, body: func(root string) byte {
+				roots = append(roots, root)
+				return body(root)
+			}}
+		},
+	})
+	defer restore()
+
+	quiet := func(...any) {}
+	report, err := client.RunWasmVerify(client.WasmVerifyArgs{WasmBuildArgs: client.WasmBuildArgs{Stdlib: true, Log: quiet}, Keep: true})
+	if err != nil {
+		t.Fatalf("RunWasmVerify: %v", err)
+	}
+	for _, dir := range report.Kept {
+		defer os.RemoveAll(dir)
+	}
+	if !report.Reproducible || len(report.Builds) != 2 || report.Builds[0].SHA256 != report.Builds[1].SHA256 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Toolchain != "go1.25.2" || report.Compiler != "go" {
+		t.Errorf("toolchain %q, compiler %q", report.Toolchain, report.Compiler)
+	}
+	if len(roots) != 2 || roots[0] == roots[1] || len(report.Kept) != 2 {
+		t.Fatalf("builds did not run in two copies: %v, kept %v", roots, report.Kept)
+	}
+	for _, c := range cfgs {
+		if !c.Reproducible {
+			t.Error("verify build without Reproducible")
+		}
+	}
+	copied := roots[0]
+	if _, err := os.Stat(filepath.Join(copied, "web", "components", "cart.go")); err != nil {
+		t.Errorf("project file not copied: %v", err)
+	}
+	for _, skipped := range []string{".git", "node_modules"} {
+		if _, err := os.Stat(filepath.Join(copied, skipped)); err == nil {
+			t.Errorf("%s was copied", skipped)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(copied, "web", "public", "client.wasm")); strings.Contains(string(data), "stale") {
+		t.Error("output directory was copied")
+	}
+
+	// a body depending on the copy's path is located in the code section
+	body = func(root string) byte {
+		if strings.Contains(root, "verify-a-") {
+			return 0x01
+		}
+		return 0x00
+	}
+	report, err = client.RunWasmVerify(client.WasmVerifyArgs{WasmBuildArgs: client.WasmBuildArgs{Stdlib: true, Log: quiet}})
+	if client.ExitCode(err) != 7 || report == nil || report.Reproducible {
+		t.Fatalf("differing builds: exit %d, report %+v", client.ExitCode(err), report)
+	}
+	if d := report.Diff; d.Section != "code" || d.Function != "#2 main.main" || d.Offset != 27 {
+		t.Errorf("diff = %+v", d)
+	}
+	if !strings.Contains(report.Summary(), "function #2 main.main") {
+		t.Errorf("summary:\n%s", report.Summary())
+	}
+
+	if _, err := client.RunWasmVerify(client.WasmVerifyArgs{WasmBuildArgs: client.WasmBuildArgs{Mode: "debug", Log: quiet}}); client.ExitCode(err) != 2 || !strings.Contains(err.Error(), "modes L and S") {
+		t.Errorf("mode M: exit %d, err %v", client.ExitCode(err), err)
+	}
+
+	if err := os.Remove("go.mod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RunWasmVerify(client.WasmVerifyArgs{WasmBuildArgs: client.WasmBuildArgs{Stdlib: true, Log: quiet}}); client.ExitCode(err) != 2 {
+		t.Errorf("outside the module root: exit %d, want 2", client.ExitCode(err))
+	}
+}
@@ -0,0 +1,72 @@
+package client_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tinywasm/client"
+)
+
+func TestWatchRebuildsOnClosureChanges(t *testing.T) {
+	root := t.TempDir()
+	writeModule(t, root, map[string]string{
+		"web/client.go":    "package main\n\nimport \"example.com/app/lib\"\n\nfunc main() { lib.Do() }\n",
+		"lib/lib.go":       "package lib\n\nfunc Do() {}\n",
+		"tools/gen.go":     "package tools\n",
+		"web/public/x.css": "body{}",
+	})
+
+	c := client.New(nil)
+	c.SetAppRootDir(root)
+	large := newFakeCompiler()
+	c.SetBuilders(large, newFakeCompiler(), newFakeCompiler())
+	c.SetActiveBuilder(large)
+	c.SetLog(func(...any) {})
+
+	results := make(chan client.WatchResult, 10)
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- c.Watch(stop, func(r client.WatchResult) { results <- r }) }()
+	defer func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("Watch: %v", err)
+		}
+	}()
+
+	// let the watcher take its first snapshot
+	time.Sleep(500 * time.Millisecond)
+
+	write := func(rel, content string) {
+		if err := os.WriteFile(filepath.Join(root, rel), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// outside the closure and inside the output dir: ignored
+	write("tools/gen.go", "package tools\n\nvar X = 1\n")
+	write("web/public/x.css", "body{color:red}")
+	select {
+	case r := <-results:
+		t.Fatalf("unexpected rebuild for %s", r.Trigger)
+	case <-time.After(time.Second):
+	}
+
+	write("lib/lib.go", "package lib\n\nfunc Do() { _ = 1 }\n")
+	select {
+	case r := <-results:
+		if r.Trigger != "lib/lib.go" || r.Event != "write" || r.Err != nil {
+			t.Errorf("unexpected result: %+v", r)
+		}
+		if r.Mode != "L" {
+			t.Errorf("mode = %q, want L", r.Mode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no rebuild after changing a file in the import closure")
+	}
+	if large.CompileCallCount != 1 {
+		t.Errorf("CompileCallCount = %d, want 1", large.CompileCallCount)
+	}
+}
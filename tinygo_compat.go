@@ -0,0 +1,171 @@
+package client
+
+import (
+	"sort"
+	"strings"
+)
+
+// Severity of a TinyGo compatibility finding.
+const (
+	TinyGoUnsupported = "unsupported" // fails to compile or to run under TinyGo
+	TinyGoDiscouraged = "discouraged" // works but bloats the binary or relies on partial reflection
+)
+
+// tinyGoRule describes why a package is flagged under TinyGo.
+type tinyGoRule struct {
+	Severity   string
+	Reason     string
+	Suggestion string
+}
+
+// tinyGoRules lists the packages flagged under TinyGo's wasm target, keyed by import path.
+// Replacements follow the stdlib table documented in templates/basic_wasm_client.md.
+var tinyGoRules = map[string]tinyGoRule{
+	"net":           {TinyGoUnsupported, "no socket support in the browser", "github.com/tinywasm/fetch"},
+	"net/http":      {TinyGoUnsupported, "net/http is not implemented for TinyGo wasm", "github.com/tinywasm/fetch"},
+	"net/rpc":       {TinyGoUnsupported, "requires net", ""},
+	"net/smtp":      {TinyGoUnsupported, "requires net", ""},
+	"crypto/tls":    {TinyGoUnsupported, "requires net", ""},
+	"os/exec":       {TinyGoUnsupported, "processes are not available in the browser", ""},
+	"os/signal":     {TinyGoUnsupported, "signals are not available in the browser", ""},
+	"plugin":        {TinyGoUnsupported, "plugins are not supported", ""},
+	"runtime/cgo":   {TinyGoUnsupported, "cgo is not available for wasm", ""},
+	"runtime/pprof": {TinyGoUnsupported, "profiling is not supported", ""},
+	"runtime/trace": {TinyGoUnsupported, "execution tracing is not supported", ""},
+
+	"fmt":           {TinyGoDiscouraged, "reflection-based formatting adds ~100KB", "github.com/tinywasm/fmt"},
+	"strconv":       {TinyGoDiscouraged, "large conversion tables", "github.com/tinywasm/fmt"},
+	"strings":       {TinyGoDiscouraged, "duplicates tinywasm/fmt helpers", "github.com/tinywasm/fmt"},
+	"encoding/json": {TinyGoDiscouraged, "relies on reflection TinyGo only partially supports", "github.com/tinywasm/json"},
+	"encoding/xml":  {TinyGoDiscouraged, "relies on reflection TinyGo only partially supports", ""},
+	"text/template": {TinyGoDiscouraged, "relies on reflection TinyGo only partially supports", ""},
+	"html/template": {TinyGoDiscouraged, "relies on reflection TinyGo only partially supports", "github.com/tinywasm/html"},
+	"time":          {TinyGoDiscouraged, "embeds timezone handling", "github.com/tinywasm/time"},
+	"reflect":       {TinyGoDiscouraged, "only partially supported by TinyGo", ""},
+	"regexp":        {TinyGoDiscouraged, "large binary size", ""},
+	"log":           {TinyGoDiscouraged, "pulls in fmt", "github.com/tinywasm/fmt"},
+}
+
+// TinyGoFinding is one flagged package of the wasm entry's import graph.
+type TinyGoFinding struct {
+	Package    string   `json:"package"`              // eg: "net/http"
+	Severity   string   `json:"severity"`             // TinyGoUnsupported or TinyGoDiscouraged
+	Reason     string   `json:"reason"`               // why it is flagged
+	Suggestion string   `json:"suggestion,omitempty"` // replacement package, "" if none
+	Chain      []string `json:"chain"`                // import chain from the entry package to Package
+}
+
+// TinyGoReport is the result of AnalyzeTinyGoCompatibility.
+type TinyGoReport struct {
+	Entry    string          `json:"entry"`    // main input file analysed, eg: web/client.go
+	Tags     []string        `json:"tags"`     // build tags used to load the graph
+	Packages int             `json:"packages"` // packages in the import graph
+	Findings []TinyGoFinding `json:"findings"`
+	// SyscallJs lists direct syscall/js uses in project files (lint only: it
+	// never makes the report incompatible).
+	SyscallJs []SyscallJsUsage `json:"syscall_js,omitempty"`
+}
+
+// Compatible reports whether no unsupported package was found.
+func (r *TinyGoReport) Compatible() bool {
+	for _, f := range r.Findings {
+		if f.Severity == TinyGoUnsupported {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary returns a short human readable description of the findings.
+func (r *TinyGoReport) Summary() string {
+	var b strings.Builder
+	if len(r.Findings) == 0 {
+		b.WriteString("✅ " + r.Entry + " is TinyGo compatible")
+	} else {
+		b.WriteString("TinyGo compatibility of " + r.Entry + ":")
+	}
+	for _, f := range r.Findings {
+		mark := "⚠️"
+		if f.Severity == TinyGoUnsupported {
+			mark = "❌"
+		}
+		b.WriteString("\n" + mark + " " + f.Package + " (" + f.Severity + "): " + f.Reason)
+		if f.Suggestion != "" {
+			b.WriteString(" → use " + f.Suggestion)
+		}
+		b.WriteString("\n   via " + strings.Join(f.Chain, " → "))
+	}
+	if len(r.SyscallJs) > 0 {
+		b.WriteString("\nDirect syscall/js use (tinywasm/dom is the only DOM library):")
+		b.WriteString(syscallJsSummary(r.SyscallJs))
+	}
+	return b.String()
+}
+
+// AnalyzeTinyGoCompatibility loads the import graph of the main input file as
+// TinyGo would compile it (GOOS=js GOARCH=wasm plus TinyGo and user build tags)
+// and reports flagged packages with the import chain that pulled each one in.
+// A package only reached through another flagged package is not reported on
+// its own: replacing the outer one removes it too. Direct syscall/js uses in
+// project files are linted as well (see LintSyscallJs).
+func (w *WasmClient) AnalyzeTinyGoCompatibility() (*TinyGoReport, error) {
+	g, err := w.loadPackageGraph(w.buildSmallSizeShortcut)
+	if err != nil {
+		return nil, err
+	}
+
+	chains := g.walk(func(path string) bool {
+		_, flagged := tinyGoRules[path]
+		return flagged
+	})
+
+	report := &TinyGoReport{
+		Entry:     w.MainInputFileRelativePath(),
+		Tags:      g.Tags,
+		Packages:  len(g.Packages),
+		Findings:  []TinyGoFinding{},
+		SyscallJs: w.lintSyscallJs(g),
+	}
+	for path, chain := range chains {
+		rule, flagged := tinyGoRules[path]
+		if !flagged {
+			continue
+		}
+		report.Findings = append(report.Findings, TinyGoFinding{
+			Package:    path,
+			Severity:   rule.Severity,
+			Reason:     rule.Reason,
+			Suggestion: rule.Suggestion,
+			Chain:      displayChain(chain, report.Entry),
+		})
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity == TinyGoUnsupported
+		}
+		return a.Package < b.Package
+	})
+	return report, nil
+}
+
+// displayChain replaces go list's "command-line-arguments" with the entry file.
+func displayChain(chain []string, entry string) []string {
+	out := make([]string, len(chain))
+	copy(out, chain)
+	if len(out) > 0 {
+		out[0] = entry
+	}
+	return out
+}
+
+// VerifyTinyGoProjectCompatibility logs the TinyGo compatibility report of the wasm entry package.
+func (w *WasmClient) VerifyTinyGoProjectCompatibility() {
+	report, err := w.AnalyzeTinyGoCompatibility()
+	if err != nil {
+		w.Logger("Error analyzing TinyGo compatibility:", err)
+		return
+	}
+	w.Logger(report.Summary())
+}
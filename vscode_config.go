@@ -30,13 +30,28 @@ func (w *WasmClient) VisualStudioCodeWasmEnvConfig() { // Create .vscode directo
 		w.makeDirectoryHiddenWindows(vscodeDir)
 	}
 
-	// Configure settings.json
-	settingsPath := filepath.Join(vscodeDir, "settings.json")
+	settingsPath, _, data, err := w.vscodeSettings()
+	if err != nil {
+		w.Logger("Warning: marshaling VS Code settings:", err)
+		return
+	}
+
+	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
+		w.Logger("Warning: writing VS Code settings:", err)
+		return
+	}
+}
+
+// vscodeSettings returns the path of .vscode/settings.json with its current
+// content (nil when missing) and the content VisualStudioCodeWasmEnvConfig writes.
+func (w *WasmClient) vscodeSettings() (path string, before, after []byte, err error) {
+	path = filepath.Join(w.AppRootDir, ".vscode", "settings.json")
 
 	var settings map[string]any
 
 	// Load existing settings if file exists
-	if data, err := os.ReadFile(settingsPath); err == nil {
+	if data, err := os.ReadFile(path); err == nil {
+		before = data
 		if err := json.Unmarshal(data, &settings); err != nil {
 			settings = make(map[string]any)
 		}
@@ -56,17 +71,8 @@ func (w *WasmClient) VisualStudioCodeWasmEnvConfig() { // Create .vscode directo
 	settings["go.alternateTools"] = map[string]string{
 		"go": "go", // Use system Go for testing and building
 	}
-	// Write updated settings
-	data, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		w.Logger("Warning: marshaling VS Code settings:", err)
-		return
-	}
-
-	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
-		w.Logger("Warning: writing VS Code settings:", err)
-		return
-	}
+	after, err = json.MarshalIndent(settings, "", "  ")
+	return path, before, after, err
 }
 
 // makeDirectoryHiddenWindows makes a directory hidden on Windows using the attrib command.
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// serverOnlyImports are packages that have no business in the browser; a file
+// of the wasm closure importing one usually lacks a `//go:build !wasm` line.
+// A "/..." suffix also matches the subpackages, as in go list patterns.
+var serverOnlyImports = []string{
+	"net",
+	"net/http/...",
+	"net/rpc/...",
+	"net/smtp",
+	"crypto/tls",
+	"database/sql/...",
+	"log/syslog",
+	"os/exec",
+	"os/signal",
+	"os/user",
+	"plugin",
+	"runtime/cgo",
+	"github.com/tinywasm/server/...",
+}
+
+// isServerOnlyImport reports whether path matches one of serverOnlyImports.
+func isServerOnlyImport(path string) bool {
+	for _, p := range serverOnlyImports {
+		if tree, ok := strings.CutSuffix(p, "/..."); ok {
+			if path == tree || strings.HasPrefix(path, tree+"/") {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// WasmPackageFiles is one package compiled into the wasm binary.
+type WasmPackageFiles struct {
+	ImportPath string   // eg: "example.com/app/lib"
+	Standard   bool     // part of the Go standard library
+	Files      []string // compiled files, relative to AppRootDir for project files
+	Excluded   []string // files of the package left out by GOOS/GOARCH/tags (project packages only)
+}
+
+// ServerImportFinding is a file compiled into the wasm binary that imports a server-only package.
+type ServerImportFinding struct {
+	File    string   // relative to AppRootDir for project files
+	Line    int      // line of the import
+	Import  string   // eg: "database/sql"
+	Package string   // import path of the file's package
+	Chain   []string // import chain from the entry to Package
+}
+
+// WasmFilesReport is the result of ReportWasmFiles.
+type WasmFilesReport struct {
+	Mode     string   // size mode the report was built for, eg: "L"
+	GOOS     string   // always "js"
+	GOARCH   string   // always "wasm"
+	Tags     []string // build tags of the mode, eg: ["dev"] in L
+	Entry    string   // main input file, eg: web/client.go
+	Packages []WasmPackageFiles
+	Flagged  []ServerImportFinding
+}
+
+// Files returns every compiled file of the report, in package order.
+func (r *WasmFilesReport) Files() []string {
+	var files []string
+	for _, p := range r.Packages {
+		files = append(files, p.Files...)
+	}
+	return files
+}
+
+// Summary returns the project packages with their compiled and excluded files
+// followed by the flagged server imports. Standard packages are only counted.
+func (r *WasmFilesReport) Summary() string {
+	var b strings.Builder
+	b.WriteString("Files compiled into " + r.Entry + " (mode " + r.Mode + ", " + r.GOOS + "/" + r.GOARCH)
+	if len(r.Tags) > 0 {
+		b.WriteString(", tags " + strings.Join(r.Tags, ","))
+	}
+	b.WriteString("):")
+
+	standard, files := 0, 0
+	for _, p := range r.Packages {
+		files += len(p.Files)
+		if p.Standard {
+			standard++
+			continue
+		}
+		b.WriteString("\n" + p.ImportPath)
+		for _, f := range p.Files {
+			b.WriteString("\n   + " + f)
+		}
+		for _, f := range p.Excluded {
+			b.WriteString("\n   - " + f)
+		}
+	}
+	b.WriteString("\n" + strconv.Itoa(len(r.Packages)) + " packages (" + strconv.Itoa(standard) + " standard), " + strconv.Itoa(files) + " files")
+
+	if len(r.Flagged) == 0 {
+		b.WriteString("\n✅ no server-only imports")
+		return b.String()
+	}
+	for _, f := range r.Flagged {
+		b.WriteString("\n❌ " + f.File + ":" + strconv.Itoa(f.Line) + " imports " + f.Import)
+		if len(f.Chain) > 1 {
+			b.WriteString("\n   via " + strings.Join(f.Chain, " → "))
+		}
+	}
+	return b.String()
+}
+
+// ReportWasmFiles lists every package and file compiled into the wasm binary
+// for the current mode (GOOS=js GOARCH=wasm plus the mode's build tags, "dev"
+// in L) and flags the non-standard files importing server-only packages.
+func (w *WasmClient) ReportWasmFiles() (*WasmFilesReport, error) {
+	w.storageMu.RLock()
+	mode := w.compiledMode()
+	w.storageMu.RUnlock()
+
+	g, err := w.loadPackageGraph(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WasmFilesReport{
+		Mode:   mode,
+		GOOS:   "js",
+		GOARCH: "wasm",
+		Tags:   g.Tags,
+		Entry:  w.MainInputFileRelativePath(),
+	}
+	chains := g.walk(nil)
+	fset := token.NewFileSet()
+
+	for _, path := range g.Order {
+		p := g.Packages[path]
+		pkg := WasmPackageFiles{ImportPath: path, Standard: p.Standard}
+		if path == g.Entry {
+			pkg.ImportPath = report.Entry
+		}
+		for _, name := range p.GoFiles {
+			full := filepath.Join(p.Dir, name)
+			pkg.Files = append(pkg.Files, w.relPath(full))
+			if p.Standard {
+				continue
+			}
+
+			f, err := parser.ParseFile(fset, full, nil, parser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			for _, imp := range f.Imports {
+				importPath, _ := strconv.Unquote(imp.Path.Value)
+				if !isServerOnlyImport(importPath) {
+					continue
+				}
+				report.Flagged = append(report.Flagged, ServerImportFinding{
+					File:    w.relPath(full),
+					Line:    fset.Position(imp.Pos()).Line,
+					Import:  importPath,
+					Package: pkg.ImportPath,
+					Chain:   displayChain(chains[path], report.Entry),
+				})
+			}
+		}
+		if p.Module != nil && p.Module.Main {
+			for _, name := range p.IgnoredGoFiles {
+				pkg.Excluded = append(pkg.Excluded, w.relPath(filepath.Join(p.Dir, name)))
+			}
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	sort.SliceStable(report.Flagged, func(i, j int) bool {
+		return report.Flagged[i].File < report.Flagged[j].File
+	})
+	return report, nil
+}
@@ -0,0 +1,337 @@
+package client
+
+import (
+	"os"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/js"
+)
+
+// WasmImport is a single entry of the import section of a wasm binary.
+type WasmImport struct {
+	Module string // eg: "gojs", "wasi_snapshot_preview1"
+	Name   string // eg: "runtime.wasmExit", "fd_write"
+}
+
+func (i WasmImport) String() string {
+	return i.Module + "." + i.Name
+}
+
+// syscallJsImports are provided by both wasm_exec.js flavours under the gojs module.
+var syscallJsImports = []string{
+	"syscall/js.finalizeRef",
+	"syscall/js.stringVal",
+	"syscall/js.valueGet",
+	"syscall/js.valueSet",
+	"syscall/js.valueDelete",
+	"syscall/js.valueIndex",
+	"syscall/js.valueSetIndex",
+	"syscall/js.valueCall",
+	"syscall/js.valueInvoke",
+	"syscall/js.valueNew",
+	"syscall/js.valueLength",
+	"syscall/js.valuePrepareString",
+	"syscall/js.valueLoadString",
+	"syscall/js.valueInstanceOf",
+	"syscall/js.copyBytesToGo",
+	"syscall/js.copyBytesToJS",
+}
+
+// runtimeImports mirrors the importObject built by the wasm_exec.js embedded in
+// tinywasm/js for each runtime: module name -> function names it provides.
+var runtimeImports = map[js.Runtime]map[string][]string{
+	js.RuntimeGo: {
+		"gojs": append([]string{
+			"runtime.wasmExit",
+			"runtime.wasmWrite",
+			"runtime.resetMemoryDataView",
+			"runtime.nanotime1",
+			"runtime.walltime",
+			"runtime.scheduleTimeoutEvent",
+			"runtime.clearTimeoutEvent",
+			"runtime.getRandomData",
+			"debug",
+		}, syscallJsImports...),
+	},
+	js.RuntimeTinyGo: {
+		"wasi_snapshot_preview1": {
+			"fd_write",
+			"fd_read",
+			"fd_close",
+			"fd_fdstat_get",
+			"fd_prestat_get",
+			"fd_prestat_dir_name",
+			"fd_seek",
+			"path_open",
+			"proc_exit",
+			"random_get",
+		},
+		// TinyGo's wasm_exec.js aliases env to gojs (Go 1.20 used 'env').
+		"gojs": append([]string{"runtime.ticks", "runtime.sleepTicks"}, syscallJsImports...),
+		"env":  append([]string{"runtime.ticks", "runtime.sleepTicks"}, syscallJsImports...),
+	},
+}
+
+func runtimeName(rt js.Runtime) string {
+	if rt == js.RuntimeTinyGo {
+		return "TinyGo"
+	}
+	return "Go"
+}
+
+// WasmRuntimeMismatchError reports a binary whose imports are not provided by
+// the JS runtime of the mode it was compiled for. Serving it would freeze the
+// browser on reload, so it is never swapped in.
+type WasmRuntimeMismatchError struct {
+	Mode       string
+	Runtime    js.Runtime
+	Unexpected []WasmImport
+}
+
+func (e *WasmRuntimeMismatchError) Error() string {
+	list := make([]string, len(e.Unexpected))
+	for i, imp := range e.Unexpected {
+		list[i] = imp.String()
+	}
+	return Sprintf("wasm runtime mismatch: mode %s expects %s runtime, unexpected imports: %s",
+		e.Mode, runtimeName(e.Runtime), JoinSlice(list, ", "))
+}
+
+// isWasmModule reports whether content starts with the wasm magic number and version 1.
+func isWasmModule(content []byte) bool {
+	return len(content) >= 8 &&
+		content[0] == 0x00 && content[1] == 'a' && content[2] == 's' && content[3] == 'm' &&
+		content[4] == 0x01 && content[5] == 0x00 && content[6] == 0x00 && content[7] == 0x00
+}
+
+// ReadWasmImports parses the import section of a wasm binary.
+func ReadWasmImports(content []byte) ([]WasmImport, error) {
+	if !isWasmModule(content) {
+		return nil, Err("wasm", "binary", "invalid", "header")
+	}
+
+	r := &wasmReader{buf: content, pos: 8}
+	for r.pos < len(r.buf) {
+		id, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.uleb()
+		if err != nil {
+			return nil, err
+		}
+		end := r.pos + int(size)
+		if end > len(r.buf) || end < r.pos {
+			return nil, Err("wasm", "section", "out of bounds")
+		}
+		if id != 2 { // 2 = import section
+			r.pos = end
+			continue
+		}
+		section := &wasmReader{buf: r.buf[:end], pos: r.pos}
+		return section.imports()
+	}
+
+	return nil, nil
+}
+
+// imports decodes the body of an import section.
+func (r *wasmReader) imports() ([]WasmImport, error) {
+	count, err := r.uleb()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]WasmImport, 0, count)
+	for i := uint64(0); i < count; i++ {
+		module, err := r.name()
+		if err != nil {
+			return nil, err
+		}
+		name, err := r.name()
+		if err != nil {
+			return nil, err
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.skipImportDesc(kind); err != nil {
+			return nil, err
+		}
+		out = append(out, WasmImport{Module: module, Name: name})
+	}
+	return out, nil
+}
+
+// CheckWasmRuntime verifies that every import of a wasm binary is provided by
+// the wasm_exec.js of the given runtime. mode is only used for reporting.
+func CheckWasmRuntime(content []byte, mode string, rt js.Runtime) error {
+	imports, err := ReadWasmImports(content)
+	if err != nil {
+		return err
+	}
+
+	provided := runtimeImports[rt]
+	var unexpected []WasmImport
+	for _, imp := range imports {
+		if !containsString(provided[imp.Module], imp.Name) {
+			unexpected = append(unexpected, imp)
+		}
+	}
+
+	if len(unexpected) > 0 {
+		return &WasmRuntimeMismatchError{Mode: mode, Runtime: rt, Unexpected: unexpected}
+	}
+	return nil
+}
+
+// runtimeForMode returns the JS runtime a binary compiled in mode needs.
+func (w *WasmClient) runtimeForMode(mode string) js.Runtime {
+	if w.RequiresTinyGo(mode) {
+		return js.RuntimeTinyGo
+	}
+	return js.RuntimeGo
+}
+
+// checkCompiledRuntime validates freshly compiled content against the runtime
+// of the current mode. Content that is not a wasm module (eg: produced by a
+// fake compiler in tests) is not inspected.
+func (w *WasmClient) checkCompiledRuntime(content []byte) error {
+	if !isWasmModule(content) {
+		return nil
+	}
+	mode := w.Value()
+	return CheckWasmRuntime(content, mode, w.runtimeForMode(mode))
+}
+
+// compiledMode returns the mode of the active builder. The caller holds
+// storageMu; without it use Value.
+func (w *WasmClient) compiledMode() string {
+	if w.CurrentSizeMode == "" {
+		return w.buildLargeSizeShortcut
+	}
+	return w.CurrentSizeMode
+}
+
+// checkCompiledFile validates the binary written at path by a disk compilation.
+// On mismatch it puts back the previous binary (or removes the new one when
+// there was none) so the served file keeps matching the served runtime.
+func (w *WasmClient) checkCompiledFile(path string, previous []byte) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil // nothing was written, nothing to validate
+	}
+
+	checkErr := w.checkCompiledRuntime(content)
+	if checkErr == nil {
+		return nil
+	}
+
+	if previous != nil {
+		os.WriteFile(path, previous, 0644)
+	} else {
+		os.Remove(path)
+	}
+	return checkErr
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// wasmReader is a minimal cursor over a wasm binary.
+type wasmReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, Err("wasm", "unexpected end of binary")
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wasmReader) uleb() (uint64, error) {
+	var result uint64
+	var shift uint
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result, nil
+		}
+		shift += 7
+		if shift >= 64 {
+			return 0, Err("wasm", "invalid", "leb128")
+		}
+	}
+}
+
+func (r *wasmReader) name() (string, error) {
+	n, err := r.uleb()
+	if err != nil {
+		return "", err
+	}
+	end := r.pos + int(n)
+	if end > len(r.buf) || end < r.pos {
+		return "", Err("wasm", "name", "out of bounds")
+	}
+	s := string(r.buf[r.pos:end])
+	r.pos = end
+	return s, nil
+}
+
+func (r *wasmReader) limits() error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.uleb(); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		_, err = r.uleb()
+	}
+	return err
+}
+
+// skipImportDesc advances past the descriptor of an import of the given kind.
+func (r *wasmReader) skipImportDesc(kind byte) error {
+	switch kind {
+	case 0x00: // func: type index
+		_, err := r.uleb()
+		return err
+	case 0x01: // table: reftype + limits
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		return r.limits()
+	case 0x02: // memory: limits
+		return r.limits()
+	case 0x03: // global: valtype + mutability
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		_, err := r.byte()
+		return err
+	case 0x04: // tag: attribute + type index
+		if _, err := r.byte(); err != nil {
+			return err
+		}
+		_, err := r.uleb()
+		return err
+	}
+	return Err("wasm", "import", "kind", "unknown:", int(kind))
+}
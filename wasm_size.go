@@ -0,0 +1,248 @@
+package client
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmSectionSize is the size of one section of a wasm binary.
+type WasmSectionSize struct {
+	Section string `json:"section"` // eg: "code", "data", "custom:name"
+	Size    int64  `json:"size"`    // bytes, id and size prefix included
+}
+
+// WasmPackageSize is the code attributed to one Go package.
+type WasmPackageSize struct {
+	Package   string `json:"package"`   // eg: "fmt", "github.com/tinywasm/dom", "(other)"
+	Size      int64  `json:"size"`      // bytes of its function bodies
+	Functions int    `json:"functions"` // number of functions
+}
+
+// WasmSizeReport is the result of InspectWasm: where the bytes of a binary go.
+type WasmSizeReport struct {
+	Mode     string            `json:"mode,omitempty"`
+	Size     int64             `json:"size"`
+	GzipSize int64             `json:"gzip_size"`
+	Sections []WasmSectionSize `json:"sections"` // largest first
+	// Packages splits the code section by the package of each function name,
+	// largest first; empty when the binary has no name section.
+	Packages []WasmPackageSize `json:"packages,omitempty"`
+	Note     string            `json:"note,omitempty"`
+}
+
+// InspectWasm measures the sections of a wasm binary and attributes its code
+// to Go packages using the function names of the name section. packages are
+// the import paths compiled in, used to recognise them in the names (see
+// wasmFunctionPackage). Binaries without a name section (TinyGo's -no-debug
+// in S mode) only get the sections.
+func InspectWasm(content []byte, packages ...string) (*WasmSizeReport, error) {
+	sections, err := wasmSections(content)
+	if err != nil {
+		return nil, err
+	}
+	r := &WasmSizeReport{Size: int64(len(content)), GzipSize: gzipSize(content), Sections: []WasmSectionSize{}}
+	for _, s := range sections {
+		r.Sections = append(r.Sections, WasmSectionSize{Section: s.label(), Size: int64(s.end - s.start)})
+	}
+	sort.SliceStable(r.Sections, func(i, j int) bool { return r.Sections[i].Size > r.Sections[j].Size })
+
+	names := wasmFunctionNames(content, sections)
+	if names == nil {
+		r.Note = "no name section: per-package sizes need a build with debug info (M or L)"
+		return r, nil
+	}
+	known := make(map[string]string, 2*len(packages))
+	for _, p := range packages {
+		known[p], known[wasmLinkerName(p)] = p, p
+	}
+	imported := wasmImportedFuncs(content, sections)
+	byPackage := map[string]*WasmPackageSize{}
+	for _, s := range sections {
+		if s.id != 10 {
+			continue
+		}
+		b := content[:s.end]
+		count, i, err := readULEB(b, s.body)
+		for n := 0; err == nil && n < int(count); n++ {
+			var size uint64
+			start := i
+			if size, i, err = readULEB(b, i); err != nil {
+				break
+			}
+			i += int(size)
+			pkg := wasmFunctionPackage(names[imported+n], known)
+			p := byPackage[pkg]
+			if p == nil {
+				p = &WasmPackageSize{Package: pkg}
+				byPackage[pkg] = p
+			}
+			p.Size += int64(i - start)
+			p.Functions++
+		}
+	}
+	for _, p := range byPackage {
+		r.Packages = append(r.Packages, *p)
+	}
+	sort.Slice(r.Packages, func(i, j int) bool {
+		if r.Packages[i].Size != r.Packages[j].Size {
+			return r.Packages[i].Size > r.Packages[j].Size
+		}
+		return r.Packages[i].Package < r.Packages[j].Package
+	})
+	return r, nil
+}
+
+// wasmFunctionPackage returns the package of a Go function name, eg:
+// "fmt.(*pp).doPrintf" and TinyGo's "(*fmt.pp).doPrintf" give "fmt". The Go
+// linker writes "/" and other symbols as "_" (github.com_tinywasm_dom.Get), so
+// known maps those spellings back to import paths; unknown names are cut at
+// their first dot. Names with no package (runtime helpers, libc, compiler
+// generated) give "(other)".
+func wasmFunctionPackage(name string, known map[string]string) string {
+	name = strings.TrimLeft(name, "(*")
+	for i := strings.LastIndex(name, "."); i > 0; i = strings.LastIndex(name[:i], ".") {
+		if pkg, ok := known[name[:i]]; ok {
+			return pkg
+		}
+	}
+	path := name
+	if i := strings.IndexAny(path, "()[ "); i >= 0 {
+		path = path[:i]
+	}
+	slash := strings.LastIndex(path, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot <= 0 {
+		return "(other)"
+	}
+	pkg := name[:slash+1+dot]
+	if strings.ContainsAny(pkg, ":$") || pkg == "type_" || pkg == "go_" {
+		return "(other)"
+	}
+	return pkg
+}
+
+// wasmLinkerName spells an import path as the Go linker writes it in the name
+// section: every character other than letters, digits, '_' and '.' becomes '_'.
+func wasmLinkerName(path string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, path)
+}
+
+// Top returns a copy of r keeping the n largest packages; the rest are folded
+// into one "(N more packages)" entry.
+func (r *WasmSizeReport) Top(n int) *WasmSizeReport {
+	out := *r
+	if len(r.Packages) <= n {
+		return &out
+	}
+	out.Packages = append([]WasmPackageSize{}, r.Packages[:n]...)
+	rest := WasmPackageSize{Package: "(" + strconv.Itoa(len(r.Packages)-n) + " more packages)"}
+	for _, p := range r.Packages[n:] {
+		rest.Size += p.Size
+		rest.Functions += p.Functions
+	}
+	out.Packages = append(out.Packages, rest)
+	return &out
+}
+
+// Summary returns the total size, the sections and the packages, one per line.
+func (r *WasmSizeReport) Summary() string {
+	var b strings.Builder
+	if r.Mode != "" {
+		b.WriteString("mode " + r.Mode + ": ")
+	}
+	b.WriteString(formatBytes(r.Size) + " (" + formatBytes(r.GzipSize) + " gzip)")
+	b.WriteString("\nsections:")
+	for _, s := range r.Sections {
+		b.WriteString("\n   " + s.Section + " " + formatBytes(s.Size) + " " + percentOf(s.Size, r.Size))
+	}
+	if len(r.Packages) > 0 {
+		b.WriteString("\ncode by package:")
+		for _, p := range r.Packages {
+			b.WriteString("\n   " + p.Package + " " + formatBytes(p.Size) + " " + percentOf(p.Size, r.Size) + ", " + strconv.Itoa(p.Functions) + " functions")
+		}
+	}
+	if r.Note != "" {
+		b.WriteString("\n" + r.Note)
+	}
+	return b.String()
+}
+
+// percentOf formats part as a percentage of total, eg: "(12.5%)".
+func percentOf(part, total int64) string {
+	if total == 0 {
+		return "(0%)"
+	}
+	return "(" + strconv.FormatFloat(float64(part)*100/float64(total), 'f', 1, 64) + "%)"
+}
+
+// SizeReport inspects the binary of mode (see InspectWasm). With mode empty or
+// the current one it reads the binary the client serves; another mode is
+// compiled in memory first, as CompileAndWait does, bounded by timeout.
+func (w *WasmClient) SizeReport(mode string, timeout time.Duration) (*WasmSizeReport, error) {
+	if timeout <= 0 {
+		timeout = DefaultCompileTimeout
+	}
+	mode, profile, err := w.modeOverride(mode)
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	if mode == "" {
+		mode = w.Value()
+		if content, err = w.servedBinary(); err != nil {
+			return nil, err
+		}
+	} else {
+		var compileErr error
+		if content, compileErr, err = w.compileModeToMemory(mode, profile, timeout); err != nil {
+			return nil, err
+		}
+		if compileErr != nil {
+			return nil, Errf("mode %s does not compile: %w", mode, compileErr)
+		}
+	}
+	var packages []string
+	if g, err := w.loadPackageGraph(mode); err == nil {
+		packages = g.Order
+	}
+	r, err := InspectWasm(content, packages...)
+	if err != nil {
+		return nil, err
+	}
+	r.Mode = mode
+	return r, nil
+}
+
+// servedBinary returns the binary of the current Storage.
+func (w *WasmClient) servedBinary() ([]byte, error) {
+	w.storageMu.RLock()
+	store, builder := w.Storage, w.activeSizeBuilder
+	w.storageMu.RUnlock()
+
+	if mem, ok := store.(*MemoryStorage); ok {
+		mem.Mu.RLock()
+		defer mem.Mu.RUnlock()
+		if len(mem.WasmContent) == 0 {
+			return nil, Err("nothing compiled yet: compile first (wasm_compile)")
+		}
+		return mem.WasmContent, nil
+	}
+	if builder == nil {
+		return nil, Err("no active builder")
+	}
+	content, err := os.ReadFile(builder.FinalOutputPath())
+	if err != nil {
+		return nil, Errf("reading the compiled binary: %w", err)
+	}
+	return content, nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	. "github.com/tinywasm/fmt"
 	"github.com/tinywasm/js"
@@ -20,6 +21,8 @@ func runtimeFromMode(mode string) js.Runtime {
 // RunWasmBuildClient captures the subset of WasmClient methods used by RunWasmBuild.
 // It is exported so tests can provide lightweight fakes without pulling in gobuild.
 type RunWasmBuildClient interface {
+	SetMainInputFile(string)
+	SetOutputName(string)
 	SetMode(string)
 	UseDiskStorage()
 	SetLog(func(...any))
@@ -31,6 +34,7 @@ type runWasmBuildDeps struct {
 	ensureTinyGoInstalled func() (string, error)
 	tinyGoEnv             func() []string
 	newClient             func(*Config) RunWasmBuildClient
+	analyzeTinyGo         func(cfg *Config, mainFile string) (*TinyGoReport, error)
 }
 
 var (
@@ -44,16 +48,22 @@ var (
 		newClient: func(cfg *Config) RunWasmBuildClient {
 			return New(cfg)
 		},
+		analyzeTinyGo: func(cfg *Config, mainFile string) (*TinyGoReport, error) {
+			w := New(cfg)
+			w.SetMainInputFile(mainFile)
+			return w.AnalyzeTinyGoCompatibility()
+		},
 	}
 	wasmBuildDepsMu sync.Mutex
 )
 
-// RunWasmBuildHooks lets tests override RunWasmBuild dependencies (installer, env provider, client factory).
+// RunWasmBuildHooks lets tests override RunWasmBuild dependencies (installer, env provider, client factory, TinyGo analyzer).
 // Use SetRunWasmBuildHooks in tests to temporarily replace these functions.
 type RunWasmBuildHooks struct {
 	EnsureTinyGoInstalled func() (string, error)
 	TinyGoEnv             func() []string
 	NewClient             func(*Config) RunWasmBuildClient
+	AnalyzeTinyGo         func(cfg *Config, mainFile string) (*TinyGoReport, error)
 }
 
 // SetRunWasmBuildHooks updates RunWasmBuild dependencies for the duration of a test.
@@ -70,6 +80,9 @@ func SetRunWasmBuildHooks(h RunWasmBuildHooks) (restore func()) {
 	if h.NewClient != nil {
 		wasmBuildDeps.newClient = h.NewClient
 	}
+	if h.AnalyzeTinyGo != nil {
+		wasmBuildDeps.analyzeTinyGo = h.AnalyzeTinyGo
+	}
 	wasmBuildDepsMu.Unlock()
 
 	return func() {
@@ -79,68 +92,280 @@ func SetRunWasmBuildHooks(h RunWasmBuildHooks) (restore func()) {
 	}
 }
 
+// wasmBuildProfiles maps the built-in profile names accepted by
+// WasmBuildArgs.Mode to size modes; tinywasm.json adds custom ones (see ProjectProfile).
+var wasmBuildProfiles = map[string]string{
+	"large":  "L",
+	"dev":    "L",
+	"medium": "M",
+	"debug":  "M",
+	"small":  "S",
+	"prod":   "S",
+}
+
 // WasmBuildArgs defines the arguments for the RunWasmBuild function.
+// Empty layout fields fall back to the defaults of the tinywasm layout:
+// web/client.go compiled to web/public/client.wasm.
 type WasmBuildArgs struct {
-	Stdlib bool // true = Go standard compiler mode "L", false = TinyGo mode "S"
+	Stdlib     bool              // true = Go standard compiler mode "L", false = TinyGo mode "S"
+	OnFindings PreflightDecision // TinyGo pre-flight with unsupported packages: abort (default), proceed or large
+
+	Mode       string   // "L", "M", "S", a profile (large/dev, medium/debug, small/prod) or a custom one of tinywasm.json ("" = Stdlib decides)
+	SourceDir  string   // directory of the main file, default "web"
+	MainFile   string   // main input file inside SourceDir, default "client.go"
+	OutputDir  string   // directory for the .wasm file and script.js, default "web/public"
+	OutputName string   // name of the .wasm file without extension, default "client"
+	BuildArgs  []string // extra compiler arguments, eg: []string{"-ldflags", "-X main.version=1"}
+	Env        []string // extra environment for the compiler, eg: []string{"GOFLAGS=-mod=vendor"}
+
+	MaxSize int64 // size budget of the .wasm file in bytes, 0 = none (see ParseByteSize)
+
+	Reproducible bool   // -trimpath, no build ID or VCS stamp, pinned GOTOOLCHAIN (see Config.Reproducible)
+	Toolchain    string // GOTOOLCHAIN of reproducible builds, default go.mod's toolchain or "local"
+
+	Watch     bool            // keep running and rebuild on changes (see WasmClient.Watch)
+	WatchStop <-chan struct{} // ends the watch loop; nil = until the process exits
+
+	Log func(message ...any) // progress output, default Println (wasmbuild -json sends it to stderr)
+
+	scriptName      string // page bootstrap file name, default "script.js" (the matrix writes one per mode)
+	assetsURLPrefix string // URL folder of the .wasm file, from tinywasm.json
+	tinyGoReady     bool   // TinyGo already installed by the caller (the matrix installs it once)
 }
 
-// RunWasmBuild performs the common logic for the wasmbuild CLI.
-func RunWasmBuild(args WasmBuildArgs) error {
-	// 1. If not stdlib: call EnsureTinyGoInstalled() and get env from tinygo package
-	if !args.Stdlib {
-		_, err := wasmBuildDeps.ensureTinyGoInstalled()
-		if err != nil {
-			return Errf("error ensuring TinyGo installation: %w", err)
+// withDefaults fills the empty layout fields.
+func (a WasmBuildArgs) withDefaults() WasmBuildArgs {
+	if a.SourceDir == "" {
+		a.SourceDir = "web"
+	}
+	if a.MainFile == "" {
+		a.MainFile = "client.go"
+	}
+	if a.OutputDir == "" {
+		a.OutputDir = filepath.Join("web", "public")
+	}
+	if a.OutputName == "" {
+		a.OutputName = "client"
+	}
+	if a.Log == nil {
+		a.Log = Println
+	}
+	if a.scriptName == "" {
+		a.scriptName = "script.js"
+	}
+	return a
+}
+
+// mode resolves Mode and Stdlib to a size mode shortcut.
+func (a WasmBuildArgs) mode() (string, error) {
+	if a.Mode == "" {
+		if a.Stdlib {
+			return "L", nil
 		}
+		return "S", nil
 	}
 
-	// 2. Verify input: check that web/client.go exists
-	inputPath := filepath.Join("web", "client.go")
-	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
-		return Errf("input file not found: %s", inputPath)
+	mode := Convert(a.Mode).ToUpper().String()
+	if profile, ok := wasmBuildProfiles[Convert(a.Mode).ToLower().String()]; ok {
+		mode = profile
+	}
+	switch mode {
+	case "L", "M", "S":
+	default:
+		return "", Errf("invalid mode %q: use L, M, S, one of large, dev, medium, debug, small, prod or a profile of %s", a.Mode, ProjectConfigFile)
+	}
+	if a.Stdlib && mode != "L" {
+		return "", Errf("-stdlib conflicts with mode %s", mode)
 	}
+	return mode, nil
+}
 
-	// 3. Create output dir: web/public
-	outputDir := filepath.Join("web", "public")
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return Errf("failed to create output directory: %w", err)
+// RunWasmBuild performs the common logic for the wasmbuild CLI. Errors are
+// *WasmBuildError values (see ExitCode).
+func RunWasmBuild(args WasmBuildArgs) error {
+	// the banner and the log follow tinywasm.json, as the build did
+	_, w, args, err := buildWasm(args)
+	args = args.withDefaults()
+	if err != nil {
+		// keep watching after a compile error: the next save may fix it
+		if !args.Watch || w == nil || BuildErrorClass(err) != WasmBuildErrCompile {
+			return err
+		}
+		args.Log(err.Error())
+	}
+	if !args.Watch {
+		return nil
 	}
 
-	// 4. Generate script.js
-	mode := "S"
-	if args.Stdlib {
-		mode = "L"
+	watcher, ok := w.(interface {
+		Watch(stop <-chan struct{}, onResult func(WatchResult)) error
+	})
+	if !ok {
+		return Err("watch is not supported by this client")
 	}
+	args.Log("watching " + filepath.Join(args.SourceDir, args.MainFile) + " and its imports for changes (Ctrl+C to stop)")
+	// one compact line per rebuild instead of the client's success log
+	w.SetLog(func(...any) {})
+	return watcher.Watch(args.WatchStop, func(r WatchResult) {
+		args.Log(r.String())
+	})
+}
 
-	js.SetRuntime(runtimeFromMode(mode))
-	jsContent := js.PageBootstrap().Content
+// BuildWasm compiles once like RunWasmBuild (Watch is ignored) and returns the
+// result wasmbuild -json prints: status, artifacts with sizes and hashes,
+// durations and diagnostics. The result is never nil; on failure err is a
+// *WasmBuildError and the result carries its class.
+func BuildWasm(args WasmBuildArgs) (*WasmBuildResult, error) {
+	result, _, _, err := buildWasm(args)
+	return result, err
+}
 
-	scriptPath := filepath.Join(outputDir, "script.js")
-	if err := os.WriteFile(scriptPath, []byte(jsContent), 0644); err != nil {
-		return Errf("failed to write script.js: %w", err)
+// buildWasm runs one build and returns the client, nil when the build failed
+// before it was created, and args merged with tinywasm.json and the defaults.
+func buildWasm(args WasmBuildArgs) (*WasmBuildResult, RunWasmBuildClient, WasmBuildArgs, error) {
+	start := time.Now()
+	result := &WasmBuildResult{Artifacts: []WasmArtifact{}, Diagnostics: []WasmDiagnostic{}}
+
+	args, mode, cfg, err := wasmBuildSetup(args, result)
+	result.Durations.Setup = time.Since(start).Milliseconds()
+	if err != nil {
+		return result, nil, args, result.finish(err, start)
+	}
+	result.Mode, result.Compiler, result.MaxSize = mode, "tinygo", args.MaxSize
+	if mode == "L" {
+		result.Compiler = "go"
 	}
 
-	// 5. Compile WASM
-	// Get environment with TINYGOROOT and updated PATH (safe for subprocess injection)
-	cfg := NewConfig()
-	if !args.Stdlib {
-		cfg.Env = wasmBuildDeps.tinyGoEnv()
+	// 3. Create output dir
+	if err := os.MkdirAll(args.OutputDir, 0755); err != nil {
+		return result, nil, args, result.finish(buildErr(WasmBuildErrIO, Errf("failed to create output directory: %w", err)), start)
 	}
-	// NewConfig() defaults should be SourceDir="web" and OutputDir="web/public",
-	// but we explicitly set them based on the required layout for safety.
-	cfg.SourceDir = func() string { return "web" }
-	cfg.OutputDir = func() string { return outputDir }
 
+	// 4. Generate script.js
+	jsContent := pageBootstrap(runtimeFromMode(mode), assetURLPath(args.assetsURLPrefix, args.OutputName+".wasm"))
+
+	scriptPath := filepath.Join(args.OutputDir, args.scriptName)
+	if err := os.WriteFile(scriptPath, jsContent, 0644); err != nil {
+		return result, nil, args, result.finish(buildErr(WasmBuildErrIO, Errf("failed to write %s: %w", args.scriptName, err)), start)
+	}
+
+	// 5. Compile WASM
 	w := wasmBuildDeps.newClient(cfg)
+	w.SetMainInputFile(args.MainFile)
+	w.SetOutputName(args.OutputName)
 	w.SetMode(mode)
 	w.UseDiskStorage()
-	w.SetLog(Println)
+	w.SetLog(args.Log)
 
-	if err := w.Compile(); err != nil {
-		return Errf("WASM compilation failed: %w", err)
+	compileStart := time.Now()
+	err = w.Compile()
+	result.Durations.Compile = time.Since(compileStart).Milliseconds()
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, compileDiagnostics(err)...)
+		return result, w, args, result.finish(buildErr(compileErrorClass(err), Errf("WASM compilation failed: %w", err)), start)
 	}
-
 	w.LogSuccessState("compiled")
 
-	return nil
+	// 6. Measure the artifacts and check the size budget
+	wasmPath := filepath.Join(args.OutputDir, args.OutputName+".wasm")
+	for _, path := range []string{wasmPath, scriptPath} {
+		artifact, err := measureArtifact(path)
+		if err != nil {
+			result.Diagnostics = append(result.Diagnostics, WasmDiagnostic{Source: "output", Severity: "warning", File: filepath.ToSlash(path), Message: "artifact not found after compilation"})
+			continue
+		}
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+	if args.MaxSize > 0 && len(result.Artifacts) > 0 && result.Artifacts[0].Path == filepath.ToSlash(wasmPath) {
+		if wasm := result.Artifacts[0]; wasm.Size > args.MaxSize {
+			msg := Sprintf("%s is %d bytes, over the %d bytes budget", wasm.Path, wasm.Size, args.MaxSize)
+			result.Diagnostics = append(result.Diagnostics, WasmDiagnostic{Source: "budget", Severity: "error", File: wasm.Path, Message: msg})
+			return result, w, args, result.finish(buildErr(WasmBuildErrBudget, Err(msg)), start)
+		}
+	}
+	return result, w, args, result.finish(nil, start)
+}
+
+// wasmBuildSetup merges tinywasm.json and applies the defaults, resolves the
+// mode, verifies the input and prepares the compiler Config, running the TinyGo
+// pre-flight and install when the mode needs TinyGo. Pre-flight findings are
+// added to result.
+// Shared by RunWasmBuild and the dev server.
+func wasmBuildSetup(args WasmBuildArgs, result *WasmBuildResult) (WasmBuildArgs, string, *Config, error) {
+	args, err := resolveBuildArgs(args)
+	if err != nil {
+		return args, "", nil, err
+	}
+	mode, err := args.mode()
+	if err != nil {
+		return args, "", nil, buildErr(WasmBuildErrConfig, err)
+	}
+
+	// 1. Verify input: check that the main file exists
+	inputPath := filepath.Join(args.SourceDir, args.MainFile)
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return args, "", nil, buildErr(WasmBuildErrIO, Errf("input file not found: %s", inputPath))
+	}
+
+	cfg := NewConfig()
+	cfg.skipProjectFile = true
+	cfg.SourceDir = func() string { return args.SourceDir }
+	cfg.OutputDir = func() string { return args.OutputDir }
+	cfg.AssetsURLPrefix = args.assetsURLPrefix
+	if len(args.BuildArgs) > 0 {
+		cfg.CompilingArguments = func() []string { return args.BuildArgs }
+	}
+	cfg.Env = args.Env
+	cfg.Reproducible, cfg.Toolchain = args.Reproducible, args.Toolchain
+
+	// 2. If TinyGo: run the pre-flight, then call EnsureTinyGoInstalled()
+	if mode != "L" {
+		stdlib, report, err := wasmBuildPreflight(cfg, args.MainFile, args.OnFindings, args.Log)
+		if report != nil {
+			result.Diagnostics = append(result.Diagnostics, preflightDiagnostics(report)...)
+		}
+		if err != nil {
+			return args, "", nil, buildErr(WasmBuildErrPreflight, err)
+		}
+		if stdlib {
+			mode = "L"
+		}
+	}
+	if mode != "L" {
+		if !args.tinyGoReady {
+			if _, err := wasmBuildDeps.ensureTinyGoInstalled(); err != nil {
+				return args, "", nil, buildErr(WasmBuildErrToolchain, Errf("error ensuring TinyGo installation: %w", err))
+			}
+		}
+		// Get environment with TINYGOROOT and updated PATH (safe for subprocess injection)
+		cfg.Env = append(wasmBuildDeps.tinyGoEnv(), args.Env...)
+	}
+	return args, mode, cfg, nil
+}
+
+// wasmBuildPreflight analyzes mainFile for TinyGo and applies decision when
+// unsupported packages are found. It returns true when the build must fall back
+// to the Go stdlib compiler. Analyzer failures are logged and never block.
+func wasmBuildPreflight(cfg *Config, mainFile string, decision PreflightDecision, log func(...any)) (stdlib bool, report *TinyGoReport, err error) {
+	report, err = wasmBuildDeps.analyzeTinyGo(cfg, mainFile)
+	if err != nil {
+		log("TinyGo pre-flight skipped:", err)
+		return false, nil, nil
+	}
+	if len(report.Findings) > 0 {
+		log(report.Summary())
+	}
+	if report.Compatible() {
+		return false, report, nil
+	}
+
+	switch decision {
+	case PreflightProceed:
+		return false, report, nil
+	case PreflightLarge:
+		log("Building with the Go stdlib compiler instead (-stdlib)")
+		return true, report, nil
+	default:
+		return false, report, Err("TinyGo pre-flight found unsupported packages; fix them, use -stdlib or -on-findings=proceed")
+	}
 }
@@ -0,0 +1,25 @@
+package client
+
+import (
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmDoctorArgs defines the arguments for the RunWasmDoctor function.
+type WasmDoctorArgs struct {
+	RootDir string // project root holding tinywasm.json and go.mod, default "."
+}
+
+// RunWasmDoctor performs the logic of the `wasmbuild doctor` subcommand: it
+// checks the toolchain and the project of RootDir (see WasmClient.Doctor) and
+// fails when any check failed. Warnings do not fail.
+func RunWasmDoctor(args WasmDoctorArgs) (*DoctorReport, error) {
+	w := New(NewConfig())
+	if args.RootDir != "" && args.RootDir != "." {
+		w.SetAppRootDir(args.RootDir)
+	}
+	report := w.Doctor()
+	if n := report.Count(DoctorFail); n > 0 {
+		return report, Errf("%d doctor checks failed", n)
+	}
+	return report, nil
+}
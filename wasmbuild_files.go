@@ -0,0 +1,61 @@
+package client
+
+import (
+	"os"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmFilesArgs defines the arguments for the RunWasmFiles function.
+type WasmFilesArgs struct {
+	Stdlib bool // report for mode "L" (Go standard compiler, dev tag) instead of tinywasm.json's mode (default S)
+}
+
+// RunWasmFiles performs the logic of the `wasmbuild files` subcommand: it
+// prints the files compiled into the main file's wasm binary (web/client.go
+// unless tinywasm.json says otherwise) for the mode a build would use, and
+// fails when one of them imports a server-only package.
+func RunWasmFiles(args WasmFilesArgs) error {
+	w := New(NewConfig())
+	if err := w.ProjectConfigError(); err != nil {
+		return buildErr(WasmBuildErrConfig, err)
+	}
+	inputPath := w.MainInputFileRelativePath()
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return Errf("input file not found: %s", inputPath)
+	}
+
+	mode, err := toolMode(w, args.Stdlib)
+	if err != nil {
+		return err
+	}
+	w.SetMode(mode)
+	report, err := w.ReportWasmFiles()
+	if err != nil {
+		return Errf("listing wasm files failed: %w", err)
+	}
+	Println(report.Summary())
+
+	if len(report.Flagged) > 0 {
+		return Errf("%d server-only imports compiled into the wasm binary", len(report.Flagged))
+	}
+	return nil
+}
+
+// toolMode resolves the mode of the files and lint subcommands as wasmbuild
+// does (-stdlib, else tinywasm.json's mode or profile, else S) and returns
+// its shortcut in w. -stdlib drops the file's profile, as in a build.
+func toolMode(w *WasmClient, stdlib bool) (string, error) {
+	args, err := resolveBuildArgs(WasmBuildArgs{Stdlib: stdlib})
+	if err != nil {
+		return "", err
+	}
+	mode, err := args.mode()
+	if err != nil {
+		return "", buildErr(WasmBuildErrConfig, err)
+	}
+	if stdlib {
+		w.useProfile("")
+	}
+	return w.shortcutOf(mode), nil
+}
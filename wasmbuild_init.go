@@ -0,0 +1,88 @@
+package client
+
+// WasmInitArgs defines the arguments for RunWasmInit and RunWasmInitPlan.
+type WasmInitArgs struct {
+	Template      string // template name, "" selects DefaultClientTemplate (see WasmClient.Templates)
+	TemplateDir   string // directory of user templates, overrides tinywasm.json's template_dir
+	SkipIDEConfig bool   // leave .vscode/settings.json alone
+	AppName       string // {{app_name}}, "" takes the last element of ModulePath or DefaultTemplateVars
+	MountID       string // {{mount_id}}, "" takes DefaultTemplateVars
+	ModulePath    string // {{module_path}}, "" takes go.mod's module
+	OutputName    string // {{output_name}}, "" takes the client's .wasm file name
+}
+
+// RunWasmInit performs the logic of the `wasmbuild init` subcommand: it
+// scaffolds the template into the source directory (the entry point as
+// web/client.go unless tinywasm.json says otherwise) and returns what was
+// created and skipped. Existing files are never overwritten; a build failure
+// of the new files is not an error but the result's BuildError.
+func RunWasmInit(args WasmInitArgs) (*ScaffoldResult, error) {
+	w, opts, err := initScaffold(args)
+	if err != nil {
+		return nil, err
+	}
+	result, err := w.Scaffold(opts)
+	if err != nil {
+		return result, buildErr(WasmBuildErrIO, err)
+	}
+	return result, nil
+}
+
+// RunWasmInitPlan performs the logic of `wasmbuild init -dry-run`: it returns
+// what RunWasmInit would do, writing nothing and running no go get. The caller
+// prints its Diff and Summary.
+func RunWasmInitPlan(args WasmInitArgs) (*ScaffoldPlan, error) {
+	w, opts, err := initScaffold(args)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := w.PlanScaffold(opts)
+	if err != nil {
+		return nil, buildErr(WasmBuildErrIO, err)
+	}
+	return plan, nil
+}
+
+// initScaffold returns the client and the scaffold options of args, checked.
+func initScaffold(args WasmInitArgs) (*WasmClient, ScaffoldOptions, error) {
+	opts := ScaffoldOptions{
+		Template:      args.Template,
+		Vars:          TemplateVars{AppName: args.AppName, MountID: args.MountID, ModulePath: args.ModulePath, OutputName: args.OutputName},
+		SkipIDEConfig: args.SkipIDEConfig,
+	}
+	w, err := newInitClient(args)
+	if err != nil {
+		return nil, opts, err
+	}
+	if _, err := w.FindTemplate(args.Template); err != nil {
+		return nil, opts, buildErr(WasmBuildErrConfig, err)
+	}
+	if err := opts.Vars.validate(); err != nil {
+		return nil, opts, buildErr(WasmBuildErrConfig, err)
+	}
+	return w, opts, nil
+}
+
+// RunWasmTemplates performs the logic of `wasmbuild init -list`: the built-in
+// templates merged with the user ones of args.TemplateDir or tinywasm.json.
+func RunWasmTemplates(args WasmInitArgs) ([]ClientTemplate, error) {
+	w, err := newInitClient(args)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := w.Templates()
+	return templates, buildErr(WasmBuildErrConfig, err)
+}
+
+// newInitClient returns the client of the working directory for the init subcommand.
+func newInitClient(args WasmInitArgs) (*WasmClient, error) {
+	w := New(NewConfig())
+	if err := w.ProjectConfigError(); err != nil {
+		return nil, buildErr(WasmBuildErrConfig, err)
+	}
+	if args.TemplateDir != "" {
+		w.Config.TemplateDir = args.TemplateDir
+	}
+	w.SetShouldCreateIDEConfig(func() bool { return !args.SkipIDEConfig })
+	return w, nil
+}
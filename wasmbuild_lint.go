@@ -0,0 +1,44 @@
+package client
+
+import (
+	"os"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmLintArgs defines the arguments for the RunWasmLint function.
+type WasmLintArgs struct {
+	Stdlib bool // lint the files of mode "L" (dev tag) instead of tinywasm.json's mode (default S)
+}
+
+// RunWasmLint performs the logic of the `wasmbuild lint` subcommand: it
+// reports direct syscall/js usage in the main file's wasm closure (web/client.go
+// unless tinywasm.json says otherwise) for the mode a build would use, and
+// fails when any is found.
+func RunWasmLint(args WasmLintArgs) error {
+	w := New(NewConfig())
+	if err := w.ProjectConfigError(); err != nil {
+		return buildErr(WasmBuildErrConfig, err)
+	}
+	inputPath := w.MainInputFileRelativePath()
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return Errf("input file not found: %s", inputPath)
+	}
+
+	mode, err := toolMode(w, args.Stdlib)
+	if err != nil {
+		return err
+	}
+	w.SetMode(mode)
+	usages, err := w.LintSyscallJs()
+	if err != nil {
+		return Errf("linting syscall/js usage failed: %w", err)
+	}
+	if len(usages) == 0 {
+		Println("✅ no direct syscall/js usage")
+		return nil
+	}
+
+	Println("Direct syscall/js use (tinywasm/dom is the only DOM library):" + syscallJsSummary(usages))
+	return Errf("%d direct syscall/js uses", len(usages))
+}
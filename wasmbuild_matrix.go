@@ -0,0 +1,248 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// wasmMatrixNames names each mode's artifacts in a matrix build, eg:
+// client-small.wasm loaded by script-small.js.
+var wasmMatrixNames = map[string]string{"L": "large", "M": "medium", "S": "small"}
+
+// WasmMatrixArgs defines the arguments for RunWasmMatrix. The build fields keep
+// their RunWasmBuild meaning except Stdlib, Mode, Watch and the output names,
+// which the matrix sets per mode.
+type WasmMatrixArgs struct {
+	WasmBuildArgs
+	Modes []string // modes or profiles to build, default L, M, S and the custom profiles of tinywasm.json
+}
+
+// WasmMatrixEntry is one mode of a matrix build.
+type WasmMatrixEntry struct {
+	Mode    string           `json:"mode"`
+	Profile string           `json:"profile,omitempty"` // custom profile of tinywasm.json, "" for a size mode
+	Result  *WasmBuildResult `json:"result"`
+	Imports int              `json:"imports"` // host imports of the binary (see ReadWasmImports), -1 without one
+}
+
+// WasmMatrixReport is the result of RunWasmMatrix, one entry per mode in the
+// requested order.
+type WasmMatrixReport struct {
+	Entries []WasmMatrixEntry `json:"entries"`
+}
+
+// Table returns the comparison table printed by wasmbuild -matrix.
+func (r *WasmMatrixReport) Table() string {
+	rows := [][]string{{"MODE", "COMPILER", "OUTPUT", "TIME", "SIZE", "GZIP", "IMPORTS"}}
+	var failures []string
+	for _, e := range r.Entries {
+		res := e.Result
+		label := e.Mode
+		if e.Profile != "" {
+			label = e.Profile + " (" + e.Mode + ")"
+		}
+		row := []string{label, res.Compiler, "-", formatMillis(res.Durations.Total), "-", "-", "-"}
+		for _, a := range res.Artifacts {
+			if strings.HasSuffix(a.Path, ".wasm") {
+				row[2], row[4], row[5] = filepath.Base(a.Path), formatBytes(a.Size), formatBytes(a.GzipSize)
+			}
+		}
+		if e.Imports >= 0 {
+			row[6] = strconv.Itoa(e.Imports)
+		}
+		if res.Status != "ok" {
+			row[2] = "✗ " + string(res.Class)
+			failures = append(failures, label+": "+res.Error)
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	var b strings.Builder
+	for n, row := range rows {
+		if n > 0 {
+			b.WriteString("\n")
+		}
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			if i == len(row)-1 {
+				b.WriteString(cell)
+				continue
+			}
+			b.WriteString(cell + strings.Repeat(" ", widths[i]-len([]rune(cell))))
+		}
+	}
+	for _, f := range failures {
+		b.WriteString("\n" + f)
+	}
+	return b.String()
+}
+
+// formatBytes formats n like the builders' BinarySize, eg: "412.3 KB".
+func formatBytes(n int64) string {
+	if n >= 1<<20 {
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + " MB"
+	}
+	return strconv.FormatFloat(float64(n)/(1<<10), 'f', 1, 64) + " KB"
+}
+
+// formatMillis formats a duration in milliseconds, eg: "820ms", "2.5s".
+func formatMillis(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// matrixBuild is one requested mode or profile of a matrix build.
+type matrixBuild struct {
+	name string // size mode, or the custom profile's name
+	mode string // L, M or S
+	args WasmBuildArgs
+}
+
+// RunWasmMatrix builds every mode or profile of args.Modes into OutputDir side
+// by side (<name>-large.wasm with script-large.js, <name>-staging.wasm with
+// script-staging.js for a custom profile, ...) and measures them. Each one is
+// resolved on its own against tinywasm.json, so a custom profile adds its
+// arguments and env to its build only. The default is L, M, S and the custom
+// profiles of tinywasm.json. Builds run in parallel once TinyGo is installed:
+// each one has its own output and temp files. A failed build does not stop
+// the others; the error reports how many failed, with the class of the first.
+func RunWasmMatrix(args WasmMatrixArgs) (*WasmMatrixReport, error) {
+	pc, err := LoadProjectConfig(".")
+	if err != nil {
+		return nil, buildErr(WasmBuildErrConfig, err)
+	}
+
+	requested := args.Modes
+	if len(requested) == 0 {
+		requested = []string{"L", "M", "S"}
+		if pc != nil {
+			var names []string
+			for name := range pc.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			requested = append(requested, names...)
+		}
+	}
+	var builds []matrixBuild
+	for _, m := range requested {
+		a := args.WasmBuildArgs
+		a.Stdlib, a.Watch = false, false
+		a.Mode = strings.TrimSpace(m)
+		name := ""
+		if pc != nil {
+			if _, custom := pc.profile(a.Mode); custom {
+				name = Convert(a.Mode).ToLower().String()
+			}
+			a = pc.applyBuildArgs(a)
+		}
+		a = a.withDefaults()
+		mode, err := a.mode()
+		if err != nil {
+			return nil, buildErr(WasmBuildErrConfig, err)
+		}
+		if name == "" {
+			name = mode
+		}
+		seen := false
+		for _, b := range builds {
+			seen = seen || b.name == name
+		}
+		if !seen {
+			builds = append(builds, matrixBuild{name: name, mode: mode, args: a})
+		}
+	}
+
+	// install TinyGo once, before builds that would race to install it
+	var toolchainErr error
+	for _, b := range builds {
+		if b.mode != "L" {
+			if _, err := wasmBuildDeps.ensureTinyGoInstalled(); err != nil {
+				toolchainErr = buildErr(WasmBuildErrToolchain, Errf("error ensuring TinyGo installation: %w", err))
+			}
+			break
+		}
+	}
+
+	var logMu sync.Mutex
+	report := &WasmMatrixReport{Entries: make([]WasmMatrixEntry, len(builds))}
+	var wg sync.WaitGroup
+	for i, b := range builds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := WasmMatrixEntry{Mode: b.mode, Imports: -1}
+			suffix := wasmMatrixNames[b.mode]
+			if b.name != b.mode {
+				entry.Profile, suffix = b.name, b.name
+			}
+
+			build := b.args
+			build.Mode = b.mode
+			build.OutputName = b.args.OutputName + "-" + suffix
+			build.scriptName = "script-" + suffix + ".js"
+			build.tinyGoReady = true
+			build.Log = func(message ...any) {
+				logMu.Lock()
+				defer logMu.Unlock()
+				b.args.Log(append([]any{"[" + b.name + "]"}, message...)...)
+			}
+
+			if b.mode != "L" && toolchainErr != nil {
+				entry.Result = &WasmBuildResult{Mode: b.mode, Compiler: "tinygo", Artifacts: []WasmArtifact{}, Diagnostics: []WasmDiagnostic{}}
+				entry.Result.finish(toolchainErr, time.Now())
+			} else {
+				entry.Result, _, _, _ = buildWasm(build)
+			}
+			if entry.Result.Status == "ok" {
+				entry.Imports = wasmImportCount(filepath.Join(build.OutputDir, build.OutputName+".wasm"))
+			}
+			report.Entries[i] = entry
+		}()
+	}
+	wg.Wait()
+
+	var firstClass WasmBuildErrorClass
+	failed := 0
+	for _, e := range report.Entries {
+		if e.Result.Status == "ok" {
+			continue
+		}
+		if failed == 0 {
+			firstClass = e.Result.Class
+		}
+		failed++
+	}
+	if failed > 0 {
+		return report, buildErr(firstClass, Errf("%d of %d matrix builds failed", failed, len(builds)))
+	}
+	return report, nil
+}
+
+// wasmImportCount returns the number of host imports of the binary at path,
+// -1 when it cannot be read or parsed.
+func wasmImportCount(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	imports, err := ReadWasmImports(content)
+	if err != nil {
+		return -1
+	}
+	return len(imports)
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmBuildErrorClass groups RunWasmBuild failures so callers (and the
+// wasmbuild exit code) can tell them apart without parsing messages.
+type WasmBuildErrorClass string
+
+const (
+	WasmBuildErrConfig    WasmBuildErrorClass = "config"    // invalid arguments, eg: unknown mode
+	WasmBuildErrToolchain WasmBuildErrorClass = "toolchain" // TinyGo or Go missing or not installable
+	WasmBuildErrCompile   WasmBuildErrorClass = "compile"   // compiler errors
+	WasmBuildErrBudget    WasmBuildErrorClass = "budget"    // binary larger than WasmBuildArgs.MaxSize
+	WasmBuildErrIO        WasmBuildErrorClass = "io"        // missing input, unwritable output
+	WasmBuildErrVerify    WasmBuildErrorClass = "verify"    // two reproducible builds differ (see RunWasmVerify)
+	WasmBuildErrPreflight WasmBuildErrorClass = "preflight" // TinyGo pre-flight found unsupported packages and aborted
+)
+
+// wasmBuildExitCodes are the wasmbuild exit codes per failure class; 1 is left
+// for unclassified errors and 2 matches the flag package's usage errors.
+var wasmBuildExitCodes = map[WasmBuildErrorClass]int{
+	WasmBuildErrConfig:    2,
+	WasmBuildErrToolchain: 3,
+	WasmBuildErrCompile:   4,
+	WasmBuildErrBudget:    5,
+	WasmBuildErrIO:        6,
+	WasmBuildErrVerify:    7,
+	WasmBuildErrPreflight: 8,
+}
+
+// WasmBuildError is an error returned by RunWasmBuild and BuildWasm with its class.
+type WasmBuildError struct {
+	Class WasmBuildErrorClass
+	Err   error
+}
+
+func (e *WasmBuildError) Error() string { return e.Err.Error() }
+func (e *WasmBuildError) Unwrap() error { return e.Err }
+
+// buildErr wraps err with class; nil stays nil.
+func buildErr(class WasmBuildErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &WasmBuildError{Class: class, Err: err}
+}
+
+// BuildErrorClass returns the class of err, or "" when it has none.
+func BuildErrorClass(err error) WasmBuildErrorClass {
+	var be *WasmBuildError
+	if errors.As(err, &be) {
+		return be.Class
+	}
+	return ""
+}
+
+// ExitCode maps err to the wasmbuild exit code: 0 on success, 2 config,
+// 3 toolchain, 4 compile, 5 budget, 6 I/O, 7 verify (not reproducible),
+// 8 preflight (TinyGo findings) and 1 for anything else.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if code, ok := wasmBuildExitCodes[BuildErrorClass(err)]; ok {
+		return code
+	}
+	return 1
+}
+
+// WasmArtifact is a file written by a build.
+type WasmArtifact struct {
+	Path     string `json:"path"`      // as given in the layout, eg: web/public/client.wasm
+	Size     int64  `json:"size"`      // bytes
+	GzipSize int64  `json:"gzip_size"` // bytes at gzip.BestCompression, as served
+	SHA256   string `json:"sha256"`
+}
+
+// WasmDiagnostic is a compiler error or a pre-flight/budget note of a build.
+type WasmDiagnostic struct {
+	Source   string `json:"source"`   // compiler, preflight, budget or output
+	Severity string `json:"severity"` // error or warning
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// WasmBuildDurations are the phases of a build in milliseconds.
+type WasmBuildDurations struct {
+	Setup   int64 `json:"setup_ms"` // pre-flight and toolchain check
+	Compile int64 `json:"compile_ms"`
+	Total   int64 `json:"total_ms"`
+}
+
+// WasmBuildResult is the outcome of BuildWasm, printed by wasmbuild -json.
+type WasmBuildResult struct {
+	Status      string              `json:"status"`          // "ok" or "error"
+	Class       WasmBuildErrorClass `json:"class,omitempty"` // failure class when Status is "error"
+	Error       string              `json:"error,omitempty"`
+	Mode        string              `json:"mode,omitempty"`     // size mode built, eg: "S"
+	Compiler    string              `json:"compiler,omitempty"` // "tinygo" or "go"
+	MaxSize     int64               `json:"max_size,omitempty"` // budget in bytes, 0 = none
+	Artifacts   []WasmArtifact      `json:"artifacts"`
+	Durations   WasmBuildDurations  `json:"durations"`
+	Diagnostics []WasmDiagnostic    `json:"diagnostics"`
+}
+
+// finish sets the status fields from err and returns err.
+func (r *WasmBuildResult) finish(err error, start time.Time) error {
+	r.Durations.Total = time.Since(start).Milliseconds()
+	if err == nil {
+		r.Status = "ok"
+		return nil
+	}
+	r.Status = "error"
+	r.Class = BuildErrorClass(err)
+	r.Error = err.Error()
+	return err
+}
+
+// measureArtifact reads path and returns its sizes and hash.
+func measureArtifact(path string) (WasmArtifact, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return WasmArtifact{}, err
+	}
+	sum := sha256.Sum256(content)
+	return WasmArtifact{
+		Path:     filepath.ToSlash(path),
+		Size:     int64(len(content)),
+		GzipSize: gzipSize(content),
+		SHA256:   hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// gzipSize returns the size of content at gzip.BestCompression, as served.
+func gzipSize(content []byte) int64 {
+	var gz bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	zw.Write(content)
+	zw.Close()
+	return int64(gz.Len())
+}
+
+// compilerDiagnosticLine matches "file.go:line:col: message" in compiler output.
+var compilerDiagnosticLine = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// compileDiagnostics extracts the file positions of a compiler error; errors
+// without any become a single diagnostic with the whole message.
+func compileDiagnostics(err error) []WasmDiagnostic {
+	var diags []WasmDiagnostic
+	for _, line := range strings.Split(err.Error(), "\n") {
+		m := compilerDiagnosticLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		d := WasmDiagnostic{Source: "compiler", Severity: "error", File: m[1], Message: m[4]}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		diags = append(diags, d)
+	}
+	if len(diags) == 0 {
+		diags = append(diags, WasmDiagnostic{Source: "compiler", Severity: "error", Message: err.Error()})
+	}
+	return diags
+}
+
+// preflightDiagnostics turns the findings of a TinyGo report into diagnostics.
+func preflightDiagnostics(report *TinyGoReport) []WasmDiagnostic {
+	var diags []WasmDiagnostic
+	for _, f := range report.Findings {
+		severity := "warning"
+		if f.Severity == TinyGoUnsupported {
+			severity = "error"
+		}
+		msg := f.Package + " (" + f.Severity + "): " + f.Reason
+		if f.Suggestion != "" {
+			msg += " → use " + f.Suggestion
+		}
+		diags = append(diags, WasmDiagnostic{Source: "preflight", Severity: severity, Message: msg})
+	}
+	for _, u := range report.SyscallJs {
+		diags = append(diags, WasmDiagnostic{
+			Source: "preflight", Severity: "warning", File: u.Path, Line: u.Line,
+			Message: u.Expr + " → use " + u.Suggestion,
+		})
+	}
+	return diags
+}
+
+// compileErrorClass tells a missing compiler apart from a compile error.
+func compileErrorClass(err error) WasmBuildErrorClass {
+	if errors.Is(err, exec.ErrNotFound) {
+		return WasmBuildErrToolchain
+	}
+	return WasmBuildErrCompile
+}
+
+// ParseByteSize parses a size such as "512000", "500KB", "1.5MB" (1 KB = 1024 bytes).
+func ParseByteSize(size string) (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(size))
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"K", 1 << 10}, {"M", 1 << 20}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(rest), unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, Errf("invalid size %q: use bytes or a KB/MB suffix, eg: 500KB", size)
+	}
+	return int64(n * multiplier), nil
+}
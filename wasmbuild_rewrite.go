@@ -0,0 +1,40 @@
+package client
+
+import (
+	"os"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmRewriteArgs defines the arguments for the RunWasmRewrite function.
+type WasmRewriteArgs struct {
+	DryRun bool // print the unified diff instead of writing the files
+}
+
+// RunWasmRewrite performs the logic of the `wasmbuild rewrite` subcommand:
+// it migrates the stdlib imports of the main file's wasm closure (web/client.go
+// unless tinywasm.json says otherwise) to tinywasm.
+func RunWasmRewrite(args WasmRewriteArgs) error {
+	w := New(NewConfig())
+	if err := w.ProjectConfigError(); err != nil {
+		return buildErr(WasmBuildErrConfig, err)
+	}
+	inputPath := w.MainInputFileRelativePath()
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return Errf("input file not found: %s", inputPath)
+	}
+
+	report, err := w.RewriteStdlibImports(args.DryRun)
+	if err != nil {
+		return Errf("rewriting imports failed: %w", err)
+	}
+
+	if args.DryRun {
+		if diff := report.Diff(); diff != "" {
+			Println(diff)
+		}
+	}
+	Println(report.Summary())
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package client
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/tinywasm/fmt"
+	"github.com/tinywasm/router"
+)
+
+// devReloadPath is the server-sent events route the served pages listen on.
+const devReloadPath = "/_wasmbuild/reload"
+
+// devReloadScript is injected before </body> of every HTML page served.
+const devReloadScript = `<script>new EventSource("` + devReloadPath + `").addEventListener("reload", () => location.reload())</script>`
+
+// devIndexPage is served at "/" when the output directory has no index.html.
+// It loads script.js from the route RegisterRoutes serves it at.
+func devIndexPage(assetsURLPrefix string) string {
+	return `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>wasmbuild serve</title>
+<script src="` + assetURLPath(assetsURLPrefix, "script.js") + `"></script>
+</head>
+<body></body>
+</html>
+`
+}
+
+// RunWasmServeClient captures the WasmClient methods used by the dev server
+// on top of RunWasmBuildClient. Test fakes returned by the NewClient hook must
+// implement it too.
+type RunWasmServeClient interface {
+	RunWasmBuildClient
+	UseMemoryStorage()
+	SetBootstrapName(string)
+	RegisterRoutes(router.Router)
+	Watch(stop <-chan struct{}, onResult func(WatchResult)) error
+}
+
+// WasmServeArgs defines the arguments for RunWasmServe. The build fields keep
+// their RunWasmBuild meaning; Watch is implied.
+type WasmServeArgs struct {
+	WasmBuildArgs
+	Addr string // listen address, default "localhost:8080"
+}
+
+// WasmDevServer serves the output directory, the in-memory wasm binary and
+// script.js, and pushes a reload to the open pages after each successful rebuild.
+type WasmDevServer struct {
+	router *HTTPRouter
+	client RunWasmServeClient
+	reload *reloadHub
+}
+
+// NewWasmDevServer prepares the build like RunWasmBuild, compiles once to memory
+// and mounts the routes: the files of OutputDir, the client's RegisterRoutes
+// (wasm binary and script.js) and the live-reload stream. A failed first
+// compilation is printed, not returned: the next save may fix it.
+func NewWasmDevServer(args WasmBuildArgs) (*WasmDevServer, error) {
+	args, mode, cfg, err := wasmBuildSetup(args, &WasmBuildResult{})
+	if err != nil {
+		return nil, err
+	}
+
+	w, ok := wasmBuildDeps.newClient(cfg).(RunWasmServeClient)
+	if !ok {
+		return nil, Err("serve is not supported by this client")
+	}
+	w.SetMainInputFile(args.MainFile)
+	w.SetOutputName(args.OutputName)
+	w.SetBootstrapName("script.js")
+	w.SetMode(mode)
+	w.UseMemoryStorage()
+	w.SetLog(args.Log)
+
+	if err := w.Compile(); err != nil {
+		args.Log("WASM compilation failed:", err)
+	} else {
+		w.LogSuccessState("compiled")
+	}
+
+	s := &WasmDevServer{router: NewHTTPRouter(), client: w, reload: &reloadHub{}}
+	s.router.Use(injectReloadScript)
+	s.router.PublicDir("/", args.OutputDir)
+	index := []byte(devIndexPage(cfg.AssetsURLPrefix))
+	s.router.PublicAsset("/{$}", func(c router.Context) {
+		if _, err := os.Stat(filepath.Join(args.OutputDir, "index.html")); err == nil {
+			serveFile(c, args.OutputDir, "index.html")
+			return
+		}
+		c.SetHeader("Content-Type", "text/html; charset=utf-8")
+		c.Write(index)
+	})
+	w.RegisterRoutes(s.router)
+	s.router.Stream(devReloadPath, s.reload.serve).Public()
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *WasmDevServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(rw, req)
+}
+
+// Router returns the router the dev server routes are mounted on, so callers
+// can add their own API routes.
+func (s *WasmDevServer) Router() *HTTPRouter {
+	return s.router
+}
+
+// Reload tells every open page to reload.
+func (s *WasmDevServer) Reload() {
+	s.reload.broadcast()
+}
+
+// Watch rebuilds on changes until stop is closed, printing one line per
+// rebuild and reloading the pages after each successful one.
+func (s *WasmDevServer) Watch(stop <-chan struct{}) error {
+	s.client.SetLog(func(...any) {})
+	return s.client.Watch(stop, func(r WatchResult) {
+		Println(r.String())
+		if r.Err == nil {
+			s.Reload()
+		}
+	})
+}
+
+// RunWasmServe runs the wasmbuild dev server until WatchStop is closed (or the
+// process exits).
+func RunWasmServe(args WasmServeArgs) error {
+	if args.Addr == "" {
+		args.Addr = "localhost:8080"
+	}
+	s, err := NewWasmDevServer(args.WasmBuildArgs)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", args.Addr)
+	if err != nil {
+		return Errf("failed to listen on %s: %w", args.Addr, err)
+	}
+	srv := &http.Server{Handler: s}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	Println("serving http://" + ln.Addr().String() + " (Ctrl+C to stop)")
+
+	// stop ends the watch on WatchStop or once RunWasmServe returns
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-args.WatchStop:
+		case <-done:
+		}
+		close(stop)
+	}()
+	watchErr := make(chan error, 1)
+	go func() { watchErr <- s.Watch(stop) }()
+
+	select {
+	case err := <-served:
+		close(done)
+		<-watchErr
+		s.reload.close()
+		return err
+	case err := <-watchErr:
+		close(done)
+		s.reload.close()
+		srv.Close()
+		return err
+	}
+}
+
+// reloadHub fans reload events out to the connected pages.
+type reloadHub struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+	closed  bool
+}
+
+func (h *reloadHub) broadcast() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- struct{}{}:
+		default: // a reload is already pending for this page
+		}
+	}
+}
+
+// close ends the open streams so the server can shut down.
+func (h *reloadHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.clients {
+		close(ch)
+		delete(h.clients, ch)
+	}
+}
+
+// serve streams reload events to one page until it disconnects (noticed through
+// Done when the Streamer has it, or by a periodic comment failing to write).
+func (h *reloadHub) serve(c router.Streamer) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	if h.clients == nil {
+		h.clients = map[chan struct{}]bool{}
+	}
+	h.clients[ch] = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, ch)
+		h.mu.Unlock()
+	}()
+
+	c.SetHeader("Content-Type", "text/event-stream")
+	c.SetHeader("Cache-Control", "no-cache")
+	c.Write([]byte(": connected\n\n"))
+	c.Flush()
+
+	var done <-chan struct{}
+	if d, ok := c.(interface{ Done() <-chan struct{} }); ok {
+		done = d.Done()
+	}
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := c.Write([]byte("event: reload\ndata: {}\n\n")); err != nil {
+				return
+			}
+		case <-ping.C:
+			if _, err := c.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		}
+		c.Flush()
+	}
+}
+
+// injectReloadScript is a middleware adding devReloadScript to HTML responses.
+func injectReloadScript(next router.HandlerFunc) router.HandlerFunc {
+	return func(c router.Context) {
+		rc := &reloadInjector{Context: c}
+		next(rc)
+		rc.finish()
+	}
+}
+
+// reloadInjector buffers an HTML response to inject the reload script; any
+// other response goes straight through.
+type reloadInjector struct {
+	router.Context
+	status  int
+	html    bool
+	decided bool
+	buf     bytes.Buffer
+}
+
+func (r *reloadInjector) SetHeader(key, value string) {
+	if strings.EqualFold(key, "Content-Type") {
+		r.html = strings.HasPrefix(value, "text/html")
+	}
+	r.Context.SetHeader(key, value)
+}
+
+func (r *reloadInjector) WriteStatus(code int) {
+	r.status = code
+}
+
+func (r *reloadInjector) Write(b []byte) (int, error) {
+	if !r.decided {
+		r.decided = true
+		if !r.html {
+			r.writeStatus()
+		}
+	}
+	if r.html {
+		return r.buf.Write(b)
+	}
+	return r.Context.Write(b)
+}
+
+func (r *reloadInjector) writeStatus() {
+	if r.status != 0 {
+		r.Context.WriteStatus(r.status)
+	}
+}
+
+// finish writes the buffered page with the script before </body>, or at the end.
+func (r *reloadInjector) finish() {
+	if !r.html {
+		if !r.decided {
+			r.writeStatus()
+		}
+		return
+	}
+	page := r.buf.String()
+	if i := strings.LastIndex(page, "</body>"); i >= 0 {
+		page = page[:i] + devReloadScript + page[i:]
+	} else {
+		page += devReloadScript
+	}
+	r.writeStatus()
+	r.Context.Write([]byte(page))
+}
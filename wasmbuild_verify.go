@@ -0,0 +1,206 @@
+package client
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/tinywasm/fmt"
+)
+
+// WasmVerifyArgs defines the arguments for RunWasmVerify. The build fields keep
+// their RunWasmBuild meaning; Reproducible is always on and Watch, MaxSize and
+// the pre-flight are ignored.
+type WasmVerifyArgs struct {
+	WasmBuildArgs
+	Keep bool // keep the two temporary copies for inspection
+}
+
+// WasmVerifyReport is the result of RunWasmVerify.
+type WasmVerifyReport struct {
+	Mode         string           `json:"mode"`
+	Compiler     string           `json:"compiler"`  // "go" or "tinygo"
+	Toolchain    string           `json:"toolchain"` // GOTOOLCHAIN of both builds
+	Builds       []WasmArtifact   `json:"builds"`    // the two binaries, inside their temporary copies
+	Reproducible bool             `json:"reproducible"`
+	Diff         *WasmSectionDiff `json:"diff,omitempty"` // first difference, nil when reproducible
+	Kept         []string         `json:"kept,omitempty"` // temporary copies left on disk by Keep
+}
+
+// Summary returns the verdict followed by the two builds and the difference.
+func (r *WasmVerifyReport) Summary() string {
+	var b strings.Builder
+	build := "mode " + r.Mode + " (" + r.Compiler + ", GOTOOLCHAIN=" + r.Toolchain + ")"
+	if r.Reproducible {
+		b.WriteString("✅ reproducible: " + build)
+	} else {
+		b.WriteString("❌ not reproducible: " + build)
+	}
+	for _, a := range r.Builds {
+		b.WriteString("\n   " + a.Path + " " + formatBytes(a.Size) + " sha256 " + a.SHA256)
+	}
+	if r.Diff != nil {
+		b.WriteString("\n   " + r.Diff.String())
+	}
+	for _, dir := range r.Kept {
+		b.WriteString("\n   kept " + dir)
+	}
+	return b.String()
+}
+
+// RunWasmVerify performs the logic of the `wasmbuild verify` subcommand: it
+// copies the project (the working directory, which must hold go.mod) into two
+// temporary directories, builds each with Reproducible on and compares the
+// binaries, reporting the first differing section. Different binaries return
+// a WasmBuildErrVerify error with the report. Mode M is rejected: its debug
+// information names the source directory.
+func RunWasmVerify(args WasmVerifyArgs) (*WasmVerifyReport, error) {
+	build, err := resolveBuildArgs(args.WasmBuildArgs)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := build.mode()
+	if err != nil {
+		return nil, buildErr(WasmBuildErrConfig, err)
+	}
+	if mode == "M" {
+		return nil, buildErr(WasmBuildErrConfig, Err("verify covers modes L and S: mode M keeps TinyGo's debug information, which names the source directory, so builds of two checkouts always differ"))
+	}
+	if _, err := os.Stat("go.mod"); err != nil {
+		return nil, buildErr(WasmBuildErrConfig, Err("verify runs from the module root: go.mod not found in the working directory"))
+	}
+	inputPath := filepath.Join(build.SourceDir, build.MainFile)
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return nil, buildErr(WasmBuildErrIO, Errf("input file not found: %s", inputPath))
+	}
+
+	report := &WasmVerifyReport{Mode: mode, Compiler: "tinygo", Toolchain: reproducibleToolchain(".", build.Toolchain), Builds: []WasmArtifact{}}
+	env := build.Env
+	if mode == "L" {
+		report.Compiler = "go"
+	} else {
+		if _, err := wasmBuildDeps.ensureTinyGoInstalled(); err != nil {
+			return nil, buildErr(WasmBuildErrToolchain, Errf("error ensuring TinyGo installation: %w", err))
+		}
+		env = append(wasmBuildDeps.tinyGoEnv(), build.Env...)
+	}
+
+	var binaries [][]byte
+	for _, name := range []string{"a", "b"} {
+		dir, err := os.MkdirTemp("", "wasmbuild-verify-"+name+"-*")
+		if err != nil {
+			return nil, buildErr(WasmBuildErrIO, err)
+		}
+		if args.Keep {
+			report.Kept = append(report.Kept, dir)
+		} else {
+			defer os.RemoveAll(dir)
+		}
+		if err := copyProjectTree(".", dir, build.OutputDir); err != nil {
+			return nil, buildErr(WasmBuildErrIO, Errf("copying the project to %s: %w", dir, err))
+		}
+		build.Log("building copy " + name + " in " + dir)
+		wasmPath, err := verifyBuild(dir, build, mode, env)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(wasmPath)
+		if err != nil {
+			return nil, buildErr(WasmBuildErrIO, Errf("reading %s: %w", wasmPath, err))
+		}
+		artifact, _ := measureArtifact(wasmPath)
+		report.Builds = append(report.Builds, artifact)
+		binaries = append(binaries, content)
+	}
+
+	report.Diff = diffWasm(binaries[0], binaries[1])
+	report.Reproducible = report.Diff == nil
+	if !report.Reproducible {
+		return report, buildErr(WasmBuildErrVerify, Errf("the two builds differ: %s", report.Diff))
+	}
+	return report, nil
+}
+
+// verifyBuild compiles the copy of the project in dir and returns the path of
+// its binary.
+func verifyBuild(dir string, args WasmBuildArgs, mode string, env []string) (string, error) {
+	cfg := NewConfig()
+	cfg.skipProjectFile = true
+	cfg.SourceDir = func() string { return args.SourceDir }
+	cfg.OutputDir = func() string { return args.OutputDir }
+	if len(args.BuildArgs) > 0 {
+		cfg.CompilingArguments = func() []string { return args.BuildArgs }
+	}
+	cfg.Env = env
+	cfg.Reproducible, cfg.Toolchain = true, args.Toolchain
+
+	w := wasmBuildDeps.newClient(cfg)
+	rooted, ok := w.(interface{ SetAppRootDir(string) })
+	if !ok {
+		return "", Err("verify is not supported by this client")
+	}
+	rooted.SetAppRootDir(dir)
+	w.SetMainInputFile(args.MainFile)
+	w.SetOutputName(args.OutputName)
+	w.SetMode(mode)
+	w.UseDiskStorage()
+	w.SetLog(args.Log)
+
+	outputDir := filepath.Join(dir, args.OutputDir)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", buildErr(WasmBuildErrIO, Errf("failed to create output directory: %w", err))
+	}
+	if err := w.Compile(); err != nil {
+		return "", buildErr(compileErrorClass(err), Errf("WASM compilation in %s failed: %w", dir, err))
+	}
+	return filepath.Join(outputDir, args.OutputName+".wasm"), nil
+}
+
+// copyProjectTree copies the regular files of src into dst, skipping hidden
+// directories, node_modules and outputDir (relative to src).
+func copyProjectTree(src, dst, outputDir string) error {
+	outputDir = filepath.Clean(outputDir)
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || rel == outputDir) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+// copyFile copies the content and permissions of src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often Watch polls the project tree for changes.
+const watchInterval = 300 * time.Millisecond
+
+// WatchResult is the outcome of one rebuild triggered by Watch.
+type WatchResult struct {
+	Trigger  string        // changed file relative to AppRootDir, eg: web/client.go
+	Event    string        // create, write or remove
+	Mode     string        // size mode used, eg: "S"
+	Duration time.Duration // time spent compiling
+	Size     string        // binary size as reported by the builder, eg: "412.3 KB"
+	Err      error         // compilation error, nil on success
+}
+
+// String returns the compact one-line form printed by wasmbuild -watch.
+func (r WatchResult) String() string {
+	line := time.Now().Format("15:04:05") + " "
+	if r.Err != nil {
+		return line + "✗ " + r.Mode + " " + r.Duration.Round(time.Millisecond).String() + " ← " + r.Trigger + ": " + r.Err.Error()
+	}
+	return line + "✓ " + r.Mode + " " + r.Size + " " + r.Duration.Round(time.Millisecond).String() + " ← " + r.Trigger
+}
+
+// fileStamp is what the watcher compares between scans.
+type fileStamp struct {
+	size int64
+	mod  time.Time
+}
+
+// fileChange is one difference between two scans.
+type fileChange struct {
+	path  string // absolute
+	event string // create, write or remove
+}
+
+// fileWatcher is a polling, recursive watcher of a directory tree. It needs no
+// OS notification API, so it behaves the same wherever wasmbuild runs.
+type fileWatcher struct {
+	root   string
+	ignore func(path string, dir bool) bool // skip a file or a whole directory
+	files  map[string]fileStamp
+}
+
+// scan walks the tree and returns the changes since the previous scan.
+// The first scan only records the current state.
+func (fw *fileWatcher) scan() []fileChange {
+	current := map[string]fileStamp{}
+	filepath.WalkDir(fw.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != fw.root && fw.ignore != nil && fw.ignore(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		current[path] = fileStamp{size: info.Size(), mod: info.ModTime()}
+		return nil
+	})
+
+	if fw.files == nil {
+		fw.files = current
+		return nil
+	}
+
+	var changes []fileChange
+	for path, stamp := range current {
+		previous, ok := fw.files[path]
+		switch {
+		case !ok:
+			changes = append(changes, fileChange{path, "create"})
+		case previous != stamp:
+			changes = append(changes, fileChange{path, "write"})
+		}
+	}
+	for path := range fw.files {
+		if _, ok := current[path]; !ok {
+			changes = append(changes, fileChange{path, "remove"})
+		}
+	}
+	fw.files = current
+	return changes
+}
+
+// Watch rebuilds the wasm binary whenever a file of the main package's import
+// closure changes, until stop is closed. The tree under AppRootDir is polled,
+// skipping hidden directories, node_modules, the output directory and
+// UnobservedFiles(). Each rebuild goes through NewFileEvent (go.mod and go.sum
+// changes recompile directly) and is reported to onResult.
+func (w *WasmClient) Watch(stop <-chan struct{}, onResult func(WatchResult)) error {
+	root, err := filepath.Abs(w.AppRootDir)
+	if err != nil {
+		return err
+	}
+	outputDir := filepath.Join(root, w.Config.OutputDir())
+
+	fw := &fileWatcher{
+		root: root,
+		ignore: func(path string, dir bool) bool {
+			name := filepath.Base(path)
+			if dir {
+				return strings.HasPrefix(name, ".") || name == "node_modules" || path == outputDir
+			}
+			return containsString(w.UnobservedFiles(), name)
+		},
+	}
+	fw.scan()
+	closure := w.watchClosure()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		trigger, ok := w.watchTrigger(fw.scan(), closure)
+		if !ok {
+			continue
+		}
+		onResult(w.watchRebuild(trigger))
+		// the change may have added or dropped imports
+		closure = w.watchClosure()
+	}
+}
+
+// watchClosure returns the directories of the project packages in the import
+// closure of the main file, or nil when the graph cannot be loaded (then any
+// .go change triggers a rebuild).
+func (w *WasmClient) watchClosure() map[string]bool {
+	w.storageMu.RLock()
+	mode := w.compiledMode()
+	w.storageMu.RUnlock()
+
+	g, err := w.loadPackageGraph(mode)
+	if err != nil {
+		return nil
+	}
+	dirs := map[string]bool{}
+	for _, p := range g.Packages {
+		if !p.Standard && p.Dir != "" {
+			dirs[p.Dir] = true
+		}
+	}
+	return dirs
+}
+
+// watchTrigger picks the change that triggers a rebuild, if any: a .go file
+// in a closure directory, or go.mod/go.sum. Several changes found in the same
+// scan produce a single rebuild.
+func (w *WasmClient) watchTrigger(changes []fileChange, closure map[string]bool) (fileChange, bool) {
+	for _, c := range changes {
+		name := filepath.Base(c.path)
+		if name == "go.mod" || name == "go.sum" {
+			return c, true
+		}
+		if filepath.Ext(name) != ".go" {
+			continue
+		}
+		if closure == nil || closure[filepath.Dir(c.path)] {
+			return c, true
+		}
+	}
+	return fileChange{}, false
+}
+
+// watchRebuild recompiles for the trigger and measures the result.
+func (w *WasmClient) watchRebuild(c fileChange) WatchResult {
+	w.storageMu.RLock()
+	mode := w.compiledMode()
+	w.storageMu.RUnlock()
+
+	result := WatchResult{Trigger: w.relPath(c.path), Event: c.event, Mode: mode}
+	start := time.Now()
+	name := filepath.Base(c.path)
+	if filepath.Ext(name) == ".go" {
+		event := c.event
+		if event == "remove" {
+			// NewFileEvent skips removals, but a removed closure file changes the build too
+			event = "write"
+		}
+		result.Err = w.NewFileEvent(name, ".go", c.path, event)
+	} else {
+		result.Err = w.RecompileMainWasm()
+	}
+	result.Duration = time.Since(start)
+	if result.Err == nil {
+		result.Size = w.binarySize()
+	}
+	return result
+}
+
+// binarySize returns the active builder's last binary size, or "unknown".
+func (w *WasmClient) binarySize() string {
+	if sizer, ok := w.activeSizeBuilder.(interface{ BinarySize() string }); ok {
+		return sizer.BinarySize()
+	}
+	return "unknown"
+}